@@ -0,0 +1,15 @@
+// Package assets ships a pinned copy of the Scalar API reference bundle so the docs can be self-hosted
+package assets
+
+import _ "embed" // Enable embed functionality
+
+// ScalarVersion is the version of the embedded @scalar/api-reference bundle, see `make update-scalar-bundle`
+const ScalarVersion = "1.25.0"
+
+// ScalarBundleName is the file name the bundle is served as, it contains the version so it can be cached forever
+const ScalarBundleName = "scalar-api-reference-" + ScalarVersion + ".js"
+
+// ScalarBundle is the standalone browser bundle of @scalar/api-reference
+//
+//go:embed scalar/standalone.js
+var ScalarBundle []byte
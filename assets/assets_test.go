@@ -0,0 +1,21 @@
+package assets_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bdpiprava/scalar-go/assets"
+)
+
+// minBundleSize is well below the size of any @scalar/api-reference standalone bundle, which is several megabytes
+const minBundleSize = 1 << 20
+
+func Test_ScalarBundle_IsNotPlaceholder(t *testing.T) {
+	if bytes.Contains(assets.ScalarBundle, []byte("make update-scalar-bundle")) {
+		t.Skip("the embedded bundle is a placeholder, run `make update-scalar-bundle` to vendor @scalar/api-reference " + assets.ScalarVersion)
+	}
+	require.GreaterOrEqual(t, len(assets.ScalarBundle), minBundleSize,
+		"the embedded bundle is too small to be @scalar/api-reference %s, run `make update-scalar-bundle`", assets.ScalarVersion)
+}
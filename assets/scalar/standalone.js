@@ -0,0 +1,5 @@
+/*
+ * Placeholder for the @scalar/api-reference standalone bundle.
+ * Run `make update-scalar-bundle` to replace it with the pinned release before shipping.
+ */
+console.error("scalar-go: the embedded @scalar/api-reference bundle is missing, run `make update-scalar-bundle`");
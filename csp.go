@@ -0,0 +1,103 @@
+package scalargo
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// scalarFontsOrigin serves the default fonts of the Scalar UI
+const scalarFontsOrigin = "https://fonts.scalar.com"
+
+// nonceSize is the number of random bytes in a generated nonce
+const nonceSize = 16
+
+// WithContentSecurityPolicy makes the Handler render the page with a fresh nonce for every request
+// and send the matching Content-Security-Policy header, see Renderer.ContentSecurityPolicy
+func WithContentSecurityPolicy() func(*Options) {
+	return func(o *Options) {
+		o.ContentSecurityPolicy = true
+	}
+}
+
+// NewNonce returns a random nonce to be used with WithNonce and Renderer.ContentSecurityPolicy
+func NewNonce() (string, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(nonce), nil
+}
+
+// ContentSecurityPolicy returns the Content-Security-Policy header value allowing the page rendered
+// with WithNonce(nonce). Scripts are only allowed with the nonce from the CDN or, when self-hosted,
+// from the same origin. Connections are allowed to the origins of the specs and the servers.
+//
+// The Scalar UI injects its styles at runtime, hence inline styles are allowed.
+func (r *Renderer) ContentSecurityPolicy(nonce string) string {
+	return contentSecurityPolicy(r.state.Load(), r.options, nonce)
+}
+
+// contentSecurityPolicy returns the Content-Security-Policy header value allowing the page rendered from
+// the state and the options with the nonce, see Renderer.ContentSecurityPolicy
+func contentSecurityPolicy(state *renderState, options *Options, nonce string) string {
+	scriptSrc := []string{fmt.Sprintf("'nonce-%s'", nonce)}
+	if src, _, err := options.scriptSource(); err == nil {
+		if scriptOrigin := origin(src); scriptOrigin != "" {
+			scriptSrc = append(scriptSrc, scriptOrigin)
+		} else {
+			scriptSrc = append(scriptSrc, "'self'")
+		}
+	}
+
+	connectSrc := []string{"'self'"}
+	for _, doc := range state.documents {
+		connectSrc = appendOrigin(connectSrc, doc.remoteURL())
+		if doc.spec != nil {
+			for _, server := range doc.spec.Servers {
+				connectSrc = appendOrigin(connectSrc, server.URL)
+			}
+		}
+	}
+	for _, key := range []string{keyProxy, keyBaseServerURL} {
+		if value, ok := options.Configurations[key].(string); ok {
+			connectSrc = appendOrigin(connectSrc, value)
+		}
+	}
+	if servers, ok := options.Configurations[keyServers].([]Server); ok {
+		for _, server := range servers {
+			connectSrc = appendOrigin(connectSrc, server.URL)
+		}
+	}
+
+	directives := []string{
+		"default-src 'self'",
+		"script-src " + strings.Join(scriptSrc, " "),
+		"style-src 'self' 'unsafe-inline' " + scalarFontsOrigin,
+		"font-src 'self' data: " + scalarFontsOrigin,
+		"img-src 'self' data: https:",
+		"connect-src " + strings.Join(connectSrc, " "),
+	}
+	return strings.Join(directives, "; ")
+}
+
+// appendOrigin appends the origin of the URL to the sources, relative URLs are covered by 'self'
+func appendOrigin(sources []string, rawURL string) []string {
+	urlOrigin := origin(rawURL)
+	if urlOrigin == "" || slices.Contains(sources, urlOrigin) {
+		return sources
+	}
+	return append(sources, urlOrigin)
+}
+
+// origin returns the scheme and host of the URL, empty for relative URLs
+func origin(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return ""
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
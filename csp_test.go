@@ -0,0 +1,126 @@
+package scalargo_test
+
+import (
+	"context"
+	stdhtml "html"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	scalargo "github.com/bdpiprava/scalar-go"
+)
+
+var tagMatcher = regexp.MustCompile(`<(script|style)[^>]*>`)
+
+func Test_Render_WithNonce(t *testing.T) {
+	renderer, err := scalargo.NewRenderer(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+		scalargo.WithOverrideCSS("h1 { color: red; }"),
+	)
+	require.NoError(t, err)
+
+	content, err := renderer.Render(context.Background(), scalargo.WithNonce("abc123"))
+	require.NoError(t, err)
+
+	tags := tagMatcher.FindAllString(content, -1)
+	require.Len(t, tags, 3)
+	for _, tag := range tags {
+		require.Contains(t, tag, `nonce="abc123"`)
+	}
+}
+
+func Test_Renderer_ContentSecurityPolicy(t *testing.T) {
+	testCases := []struct {
+		name      string
+		inputOpts []scalargo.Option
+		want      string
+	}{
+		{
+			name: "should allow CDN script and spec servers",
+			inputOpts: []scalargo.Option{
+				scalargo.WithSpecDir("./data/loader"),
+				scalargo.WithBaseFileName("pet-store.yml"),
+				scalargo.WithProxy("https://proxy.scalar.com"),
+			},
+			want: "default-src 'self'; " +
+				"script-src 'nonce-abc123' https://cdn.jsdelivr.net; " +
+				"style-src 'self' 'unsafe-inline' https://fonts.scalar.com; " +
+				"font-src 'self' data: https://fonts.scalar.com; " +
+				"img-src 'self' data: https:; " +
+				"connect-src 'self' http://petstore.swagger.io https://proxy.scalar.com",
+		},
+		{
+			name: "should allow self-hosted script and spec URL",
+			inputOpts: []scalargo.Option{
+				scalargo.WithSpecURL(galaxySpecURL),
+				scalargo.WithSelfHosted("/docs/"),
+				scalargo.WithServers(scalargo.Server{URL: "https://api.example.com/v1"}),
+			},
+			want: "default-src 'self'; " +
+				"script-src 'nonce-abc123' 'self'; " +
+				"style-src 'self' 'unsafe-inline' https://fonts.scalar.com; " +
+				"font-src 'self' data: https://fonts.scalar.com; " +
+				"img-src 'self' data: https:; " +
+				"connect-src 'self' https://cdn.jsdelivr.net https://api.example.com",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			renderer, err := scalargo.NewRenderer(tc.inputOpts...)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.want, renderer.ContentSecurityPolicy("abc123"))
+		})
+	}
+}
+
+func Test_Handler_WithContentSecurityPolicy(t *testing.T) {
+	h, err := scalargo.Handler(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+		scalargo.WithContentSecurityPolicy(),
+	)
+	require.NoError(t, err)
+
+	nonces := map[string]bool{}
+	for range 2 {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+		require.Empty(t, rec.Header().Get("ETag"))
+
+		nonce := regexp.MustCompile(`'nonce-([^']+)'`).FindStringSubmatch(rec.Header().Get("Content-Security-Policy"))
+		require.Len(t, nonce, 2)
+		require.Contains(t, stdhtml.UnescapeString(rec.Body.String()), `nonce="`+nonce[1]+`"`)
+		nonces[nonce[1]] = true
+	}
+	require.Len(t, nonces, 2)
+}
+
+func Test_Handler_WithContentSecurityPolicy_RequestHook(t *testing.T) {
+	h, err := scalargo.Handler(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+		scalargo.WithContentSecurityPolicy(),
+		scalargo.WithRequestHook(serversFromRequest),
+	)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = "acme.example.com"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	policy := rec.Header().Get("Content-Security-Policy")
+	require.Contains(t, policy, "connect-src 'self' https://acme.example.com")
+	require.NotContains(t, policy, "http://petstore.swagger.io")
+}
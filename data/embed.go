@@ -1,6 +1,11 @@
 package data
 
-import _ "embed"
+import "embed"
 
 //go:embed loader/pet-store.yml
 var PetStoreSpec []byte
+
+// MultipleFilesSpec is the pet store spec split into paths/, responses/ and schemas/
+//
+//go:embed loader-multiple-files
+var MultipleFilesSpec embed.FS
@@ -0,0 +1,131 @@
+package filter
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+// componentPrefix starts the local references to components
+const componentPrefix = "#/components/"
+
+// pruneComponents removes the components the spec no longer references, the ones it did not reference
+// before filtering are kept unless left out by their markers
+func (f *filter) pruneComponents(usedBefore, usedAfter map[string]bool) {
+	for section, entries := range f.components {
+		for name, value := range *entries {
+			key := section + "/" + name
+			if usedAfter[key] {
+				continue
+			}
+			obj, _ := normalize(value)
+			if usedBefore[key] || f.hidden(obj) {
+				delete(*entries, name)
+			}
+		}
+	}
+}
+
+// references returns the components referenced from the paths, webhooks and security requirements of
+// the spec, directly or through other components, as "section/name"
+func references(spec *model.Spec) map[string]bool {
+	sections := spec.Components.Sections()
+	used := map[string]bool{}
+
+	var visit func(value any)
+	use := func(key string) {
+		if used[key] {
+			return
+		}
+		used[key] = true
+		section, name, _ := strings.Cut(key, "/")
+		if entries := sections[section]; entries != nil {
+			visit((*entries)[name])
+		}
+	}
+	visit = func(value any) {
+		switch v := value.(type) {
+		case model.GenericObject:
+			for key, item := range v {
+				switch key {
+				case keyRef:
+					if section, name, ok := componentOf(item); ok {
+						use(section + "/" + name)
+					}
+				case "mapping":
+					// the discriminator mapping of a schema references schemas by value
+					for _, target := range asObject(item) {
+						if section, name, ok := componentOf(target); ok {
+							use(section + "/" + name)
+						}
+					}
+				case "security":
+					for _, requirement := range asList(item) {
+						for scheme := range asObject(requirement) {
+							use("securitySchemes/" + scheme)
+						}
+					}
+				}
+				visit(item)
+			}
+		case map[string]any:
+			visit(model.GenericObject(v))
+		case []any:
+			for _, item := range v {
+				visit(item)
+			}
+		}
+	}
+
+	visit(spec.Paths)
+	visit(spec.Webhooks)
+	for _, requirement := range spec.Security {
+		for scheme := range requirement {
+			use("securitySchemes/" + scheme)
+		}
+	}
+	return used
+}
+
+// componentOf returns the section and name of the component a local reference points to, or points into
+// for a reference such as "#/components/schemas/Pet/properties/id"
+func componentOf(ref any) (string, string, bool) {
+	tokens, ok := componentTokens(ref)
+	if !ok || len(tokens) < 2 {
+		return "", "", false
+	}
+	return tokens[0], tokens[1], true
+}
+
+// componentTokens returns the unescaped tokens of a local reference after "#/components/"
+func componentTokens(ref any) ([]string, bool) {
+	s, ok := ref.(string)
+	if !ok {
+		return nil, false
+	}
+	pointer, ok := strings.CutPrefix(s, componentPrefix)
+	if !ok {
+		return nil, false
+	}
+	if unescaped, err := url.PathUnescape(pointer); err == nil {
+		pointer = unescaped
+	}
+	tokens := strings.Split(pointer, "/")
+	for i, token := range tokens {
+		tokens[i] = model.UnescapeToken(token)
+	}
+	return tokens, true
+}
+
+// asObject returns the value as a GenericObject, nil when it is not an object
+func asObject(value any) model.GenericObject {
+	obj, _ := normalize(value)
+	return obj
+}
+
+// asList returns the value as a list, nil when it is not a list
+func asList(value any) []any {
+	list, _ := value.([]any)
+	return list
+}
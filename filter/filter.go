@@ -0,0 +1,279 @@
+package filter
+
+import (
+	"slices"
+
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+const (
+	keyAudience = "x-audience"
+	keyInternal = "x-internal"
+	keyRef      = "$ref"
+)
+
+// Option configures the filter
+type Option func(*filter)
+
+// filter decides which parts of a spec are kept
+type filter struct {
+	audience    string
+	includeTags []string
+	excludeTags []string
+	internal    bool
+	// tags holds the tag objects of the spec by name
+	tags map[string]model.Tag
+	// components holds the sections of the components of the spec by name
+	components map[string]*model.GenericObject
+}
+
+// ForAudience keeps the elements marked with the audience in x-audience, a name or a list of names,
+// and the ones without x-audience. Elements marked for other audiences are removed.
+func ForAudience(audience string) Option {
+	return func(f *filter) {
+		f.audience = audience
+	}
+}
+
+// IncludeTags keeps only the operations tagged with at least one of the tags
+func IncludeTags(tags ...string) Option {
+	return func(f *filter) {
+		f.includeTags = append(f.includeTags, tags...)
+	}
+}
+
+// ExcludeTags removes the operations tagged with any of the tags
+func ExcludeTags(tags ...string) Option {
+	return func(f *filter) {
+		f.excludeTags = append(f.excludeTags, tags...)
+	}
+}
+
+// KeepInternal keeps the elements marked with x-internal: true, they are removed otherwise
+func KeepInternal() Option {
+	return func(f *filter) {
+		f.internal = true
+	}
+}
+
+// Apply removes from the spec the operations, path items, webhooks, tags, parameters and schema properties
+// the options leave out. The components only used by removed elements are pruned and the tags and
+// x-tagGroups list only the tags still in use. The spec is changed in place and returned.
+func Apply(spec *model.Spec, opts ...Option) *model.Spec {
+	f := &filter{tags: map[string]model.Tag{}, components: spec.Components.Sections()}
+	for _, opt := range opts {
+		opt(f)
+	}
+	for _, tag := range spec.Tags {
+		f.tags[tag.Name] = tag
+	}
+
+	usedTags := operationTags(spec)
+	usedComponents := references(spec)
+
+	f.filterOperations(spec.Paths)
+	f.filterOperations(spec.Webhooks)
+	f.filterElements(spec.Paths)
+	f.filterElements(spec.Webhooks)
+	for _, section := range f.components {
+		f.filterElements(*section)
+	}
+
+	f.pruneComponents(usedComponents, references(spec))
+	f.filterTags(spec, usedTags, operationTags(spec))
+	return spec
+}
+
+// filterOperations removes the path items and operations left out from the section, paths or webhooks,
+// path items left without operations are removed as well
+func (f *filter) filterOperations(section model.GenericObject) {
+	for path, value := range section {
+		item, ok := normalize(value)
+		if !ok {
+			continue
+		}
+		if f.hiddenElement(item) {
+			delete(section, path)
+			continue
+		}
+
+		found, kept := 0, 0
+		for _, method := range model.HTTPMethods {
+			operation, ok := normalize(item[method])
+			if !ok {
+				continue
+			}
+			found++
+			if f.keepsOperation(operation) {
+				kept++
+			} else {
+				delete(item, method)
+			}
+		}
+		if found > 0 && kept == 0 {
+			delete(section, path)
+		}
+	}
+}
+
+// keepsOperation checks if the operation is kept by its markers and tags
+func (f *filter) keepsOperation(operation model.GenericObject) bool {
+	if f.hidden(operation) {
+		return false
+	}
+
+	tags := stringsOf(operation["tags"])
+	for _, tag := range tags {
+		if !f.keepsTag(tag) {
+			return false
+		}
+	}
+	return len(f.includeTags) == 0 || slices.ContainsFunc(tags, func(tag string) bool {
+		return slices.Contains(f.includeTags, tag)
+	})
+}
+
+// keepsTag checks if the tag is kept by the options and the markers of its tag object
+func (f *filter) keepsTag(tag string) bool {
+	if slices.Contains(f.excludeTags, tag) {
+		return false
+	}
+	if len(f.includeTags) > 0 && !slices.Contains(f.includeTags, tag) {
+		return false
+	}
+	return !f.hidden(f.tags[tag].Extensions)
+}
+
+// filterElements removes the parameters and schema properties left out from the value and the values within
+func (f *filter) filterElements(value any) {
+	switch v := value.(type) {
+	case model.GenericObject:
+		if properties, ok := normalize(v["properties"]); ok {
+			var removed []string
+			for name, property := range properties {
+				if obj, ok := normalize(property); ok && f.hiddenElement(obj) {
+					delete(properties, name)
+					removed = append(removed, name)
+				}
+			}
+			if required, ok := v["required"].([]any); ok && len(removed) > 0 {
+				v["required"] = slices.DeleteFunc(required, func(name any) bool {
+					s, _ := name.(string)
+					return slices.Contains(removed, s)
+				})
+			}
+		}
+		if parameters, ok := v["parameters"].([]any); ok {
+			v["parameters"] = slices.DeleteFunc(parameters, func(parameter any) bool {
+				obj, ok := normalize(parameter)
+				return ok && f.hiddenElement(obj)
+			})
+		}
+		for _, item := range v {
+			f.filterElements(item)
+		}
+	case map[string]any:
+		f.filterElements(model.GenericObject(v))
+	case []any:
+		for _, item := range v {
+			f.filterElements(item)
+		}
+	}
+}
+
+// filterTags removes the tags left out, and the ones only used by removed operations, from the tags
+// and x-tagGroups of the spec, groups left without tags are removed
+func (f *filter) filterTags(spec *model.Spec, usedBefore, usedAfter map[string]bool) {
+	keep := func(tag string) bool {
+		return f.keepsTag(tag) && (!usedBefore[tag] || usedAfter[tag])
+	}
+
+	spec.Tags = slices.DeleteFunc(spec.Tags, func(tag model.Tag) bool { return !keep(tag.Name) })
+	for i := range spec.TagsGroup {
+		spec.TagsGroup[i].Tags = slices.DeleteFunc(spec.TagsGroup[i].Tags, func(tag string) bool { return !keep(tag) })
+	}
+	spec.TagsGroup = slices.DeleteFunc(spec.TagsGroup, func(group model.TagGroup) bool { return len(group.Tags) == 0 })
+}
+
+// hidden checks if the element is left out by its x-internal and x-audience markers
+func (f *filter) hidden(element model.GenericObject) bool {
+	if internal, _ := element[keyInternal].(bool); internal && !f.internal {
+		return true
+	}
+	if f.audience == "" {
+		return false
+	}
+	audiences := stringsOf(element[keyAudience])
+	return len(audiences) > 0 && !slices.Contains(audiences, f.audience)
+}
+
+// hiddenElement checks if the element, or the component it references, is left out by its markers
+func (f *filter) hiddenElement(element model.GenericObject) bool {
+	return f.hidden(element) || f.hidden(f.resolve(element))
+}
+
+// resolve returns the component the element references, or the element itself when it is not a local reference
+func (f *filter) resolve(element model.GenericObject) model.GenericObject {
+	ref, ok := element[keyRef].(string)
+	if !ok {
+		return element
+	}
+	// a reference into a component, such as one of its properties, is not the component itself
+	tokens, ok := componentTokens(ref)
+	if !ok || len(tokens) != 2 || f.components[tokens[0]] == nil {
+		return element
+	}
+	if target, ok := normalize((*f.components[tokens[0]])[tokens[1]]); ok {
+		return target
+	}
+	return element
+}
+
+// operationTags returns the tags of the operations of the paths and webhooks of the spec
+func operationTags(spec *model.Spec) map[string]bool {
+	tags := map[string]bool{}
+	for _, section := range []model.GenericObject{spec.Paths, spec.Webhooks} {
+		for _, value := range section {
+			item, _ := normalize(value)
+			for _, method := range model.HTTPMethods {
+				operation, _ := normalize(item[method])
+				for _, tag := range stringsOf(operation["tags"]) {
+					tags[tag] = true
+				}
+			}
+		}
+	}
+	return tags
+}
+
+// normalize returns the value as a GenericObject when it is an object
+func normalize(value any) (model.GenericObject, bool) {
+	switch v := value.(type) {
+	case model.GenericObject:
+		return v, true
+	case map[string]any:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+// stringsOf returns the string, or the strings of the list, the value holds
+func stringsOf(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		res := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	default:
+		return nil
+	}
+}
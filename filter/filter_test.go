@@ -0,0 +1,181 @@
+package filter_test
+
+import (
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/bdpiprava/scalar-go/filter"
+	"github.com/bdpiprava/scalar-go/loader"
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+func Test_Apply(t *testing.T) {
+	testCases := []struct {
+		name           string
+		opts           []filter.Option
+		wantOperations []string
+		wantTags       []string
+		wantTagGroups  []model.TagGroup
+		wantSchemas    []string
+		wantProperties []string
+		wantRequired   []any
+		wantParameters []string
+	}{
+		{
+			name:           "public",
+			opts:           []filter.Option{filter.ForAudience("public")},
+			wantOperations: []string{"listAnimals"},
+			wantTags:       []string{"animals", "unused"},
+			wantTagGroups:  []model.TagGroup{{Name: "Zoo", Tags: []string{"animals"}}},
+			wantSchemas:    []string{"Animal", "Animals", "Documented"},
+			wantProperties: []string{"name"},
+			wantRequired:   []any{"name"},
+			wantParameters: []string{"page"},
+		},
+		{
+			name:           "partner",
+			opts:           []filter.Option{filter.ForAudience("partner")},
+			wantOperations: []string{"feedAnimal", "listAnimals", "listPartners"},
+			wantTags:       []string{"animals", "feeding", "unused"},
+			wantTagGroups:  []model.TagGroup{{Name: "Zoo", Tags: []string{"animals", "feeding"}}},
+			wantSchemas:    []string{"Animal", "Animals", "Documented", "Feeding", "Partner"},
+			wantProperties: []string{"name"},
+			wantRequired:   []any{"name"},
+			wantParameters: []string{"page"},
+		},
+		{
+			name:           "internal",
+			opts:           []filter.Option{filter.ForAudience("internal"), filter.KeepInternal()},
+			wantOperations: []string{"feedAnimal", "listAnimals", "reset"},
+			wantTags:       []string{"admin", "animals", "feeding", "unused"},
+			wantTagGroups: []model.TagGroup{
+				{Name: "Zoo", Tags: []string{"animals", "feeding"}},
+				{Name: "Operations", Tags: []string{"admin"}},
+			},
+			wantSchemas:    []string{"Animal", "Animals", "Diet", "Documented", "Feeding", "Secret"},
+			wantProperties: []string{"cost", "diet", "name"},
+			wantRequired:   []any{"name", "cost"},
+			wantParameters: []string{"#/components/parameters/Trace", "page", "keeper"},
+		},
+		{
+			name:           "by tags",
+			opts:           []filter.Option{filter.IncludeTags("animals", "feeding"), filter.ExcludeTags("feeding"), filter.KeepInternal()},
+			wantOperations: []string{"listAnimals", "listPartners"},
+			wantTags:       []string{"animals"},
+			wantTagGroups:  []model.TagGroup{{Name: "Zoo", Tags: []string{"animals"}}},
+			wantSchemas:    []string{"Animal", "Animals", "Diet", "Documented", "Partner", "Secret"},
+			wantProperties: []string{"cost", "diet", "name"},
+			wantRequired:   []any{"name", "cost"},
+			wantParameters: []string{"#/components/parameters/Trace", "page", "keeper"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec, err := loader.LoadFromDir("../data/audience", "api.yaml")
+			require.NoError(t, err)
+
+			spec = filter.Apply(spec, tc.opts...)
+
+			var operations []string
+			for op := range spec.Operations() {
+				operations = append(operations, op.Operation.OperationID)
+			}
+			sort.Strings(operations)
+			require.Equal(t, tc.wantOperations, operations)
+
+			var tags []string
+			for _, tag := range spec.Tags {
+				tags = append(tags, tag.Name)
+			}
+			sort.Strings(tags)
+			require.Equal(t, tc.wantTags, tags)
+			require.Equal(t, tc.wantTagGroups, spec.TagsGroup)
+
+			require.Equal(t, tc.wantSchemas, sortedKeys(spec.Components.Schemas))
+			animal, err := spec.Schema("Animal")
+			require.NoError(t, err)
+			require.Equal(t, tc.wantProperties, sortedKeys(animal.Properties))
+			require.Equal(t, tc.wantRequired, spec.Components.Schemas["Animal"].(model.GenericObject)["required"])
+
+			list, ok := spec.FindOperation("listAnimals")
+			require.True(t, ok)
+			var parameters []string
+			for _, parameter := range list.Parameters {
+				parameters = append(parameters, parameter.Name+parameter.Ref)
+			}
+			require.Equal(t, tc.wantParameters, parameters)
+		})
+	}
+}
+
+func Test_Apply_PrunesSecuritySchemes(t *testing.T) {
+	spec, err := loader.LoadFromDir("../data/audience", "api.yaml")
+	require.NoError(t, err)
+
+	public := filter.Apply(spec, filter.ForAudience("public"))
+	require.Empty(t, public.Components.SecuritySchemes)
+	require.Empty(t, public.Components.RequestBodies)
+	require.Empty(t, public.Components.Parameters)
+
+	spec, err = loader.LoadFromDir("../data/audience", "api.yaml")
+	require.NoError(t, err)
+
+	partner := filter.Apply(spec, filter.ForAudience("partner"))
+	require.Contains(t, partner.Components.SecuritySchemes, "partnerKey")
+	require.Contains(t, partner.Components.RequestBodies, "Feeding")
+}
+
+func Test_Apply_KeepsComponentsReferencedFromInside(t *testing.T) {
+	var spec model.Spec
+	require.NoError(t, yaml.Unmarshal([]byte(`
+openapi: 3.0.0
+info: {title: Zoo, version: 1.0.0}
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      x-internal: true
+      responses:
+        "200":
+          description: The pets
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Pet"}
+  /pets/{id}:
+    get:
+      operationId: getPet
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema: {$ref: "#/components/schemas/Pet/properties/id"}
+      responses:
+        "204": {description: Found}
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        id: {type: integer}
+`), &spec))
+
+	filtered := filter.Apply(&spec)
+	_, ok := filtered.FindOperation("listPets")
+	require.False(t, ok)
+	require.Contains(t, filtered.Components.Schemas, "Pet")
+}
+
+// sortedKeys returns the keys of the map in alphabetical order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,41 @@
+package scalargo
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"strings"
+)
+
+// NewFragment generates an embeddable fragment of the Scalar UI, see Renderer.RenderFragment
+func NewFragment(mountID string, opts ...Option) (template.HTML, error) {
+	renderer, err := NewRenderer(opts...)
+	if err != nil {
+		return "", err
+	}
+	defer renderer.Close()
+
+	return renderer.RenderFragment(context.Background(), mountID)
+}
+
+// RenderFragment generates the Scalar UI as a fragment to embed in an existing page. The fragment renders
+// into a new element with the given mount id and loads the Scalar bundle once, so several fragments
+// with different mount ids can be placed on the same page. Hot reload is not available for fragments.
+func (r *Renderer) RenderFragment(ctx context.Context, mountID string, opts ...RenderOption) (template.HTML, error) {
+	if strings.TrimSpace(mountID) == "" {
+		return "", errors.New("mount id of the fragment must not be empty")
+	}
+	r.refresh(ctx)
+
+	data, err := r.templateData(r.state.Load(), r.options, opts...)
+	if err != nil {
+		return "", err
+	}
+	data.MountID = mountID
+
+	var fragment strings.Builder
+	if err := r.template.ExecuteTemplate(&fragment, "scalar-fragment", data); err != nil {
+		return "", err
+	}
+	return template.HTML(fragment.String()), nil // #nosec G203 -- rendered by html/template
+}
@@ -0,0 +1,88 @@
+package scalargo_test
+
+import (
+	"context"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	scalargo "github.com/bdpiprava/scalar-go"
+)
+
+func Test_NewFragment(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mountID   string
+		inputOpts []scalargo.Option
+		want      []string
+		wantError string
+	}{
+		{
+			name:      "should render inline spec into the mount element",
+			mountID:   "pets",
+			inputOpts: []scalargo.Option{scalargo.WithSpecDir("./data/loader"), scalargo.WithBaseFileName("pet-store.yml")},
+			want: []string{
+				`<div id="pets"></div>`,
+				`Scalar.createApiReference(document.getElementById("pets"), {"content":{"openapi":"3.0.0","info":{"title":"Swagger Petstore"`,
+				`bundle.src = "https://cdn.jsdelivr.net/npm/@scalar/api-reference";`,
+			},
+		},
+		{
+			name:      "should render spec URL into the mount element",
+			mountID:   "galaxy",
+			inputOpts: []scalargo.Option{scalargo.WithSpecURL(galaxySpecURL), scalargo.WithOverrideCSS("h1 { color: red; }")},
+			want: []string{
+				`<style>h1 { color: red; }</style>`,
+				`<div id="galaxy"></div>`,
+				`"url":"` + galaxySpecURL + `"`,
+			},
+		},
+		{
+			name:      "should fail without mount id",
+			mountID:   " ",
+			inputOpts: []scalargo.Option{scalargo.WithSpecURL(galaxySpecURL)},
+			wantError: "mount id of the fragment must not be empty",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fragment, err := scalargo.NewFragment(tc.mountID, tc.inputOpts...)
+
+			if tc.wantError != "" {
+				require.EqualError(t, err, tc.wantError)
+				require.Empty(t, fragment)
+				return
+			}
+			require.NoError(t, err)
+			require.NotContains(t, string(fragment), "<!DOCTYPE html>")
+			for _, want := range tc.want {
+				require.Contains(t, string(fragment), want)
+			}
+		})
+	}
+}
+
+func Test_RenderFragment_MultipleOnOnePage(t *testing.T) {
+	renderer, err := scalargo.NewRenderer(scalargo.WithSpecURL(galaxySpecURL))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	first, err := renderer.RenderFragment(ctx, "first", scalargo.WithNonce("abc123"))
+	require.NoError(t, err)
+	second, err := renderer.RenderFragment(ctx, "second", scalargo.WithNonce("abc123"))
+	require.NoError(t, err)
+
+	portal := template.Must(template.New("portal").Parse(`<main>{{.First}}{{.Second}}</main>`))
+	var page strings.Builder
+	require.NoError(t, portal.Execute(&page, map[string]template.HTML{"First": first, "Second": second}))
+
+	content := page.String()
+	require.Equal(t, 1, strings.Count(content, `<div id="first"></div>`))
+	require.Equal(t, 1, strings.Count(content, `<div id="second"></div>`))
+	require.Equal(t, 2, strings.Count(content, `document.querySelector("script[data-scalar-bundle]")`))
+	require.Equal(t, 2, strings.Count(content, `<script nonce="abc123">`))
+	require.NotContains(t, content, `id="api-reference"`)
+}
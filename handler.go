@@ -0,0 +1,381 @@
+package scalargo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/bdpiprava/scalar-go/assets"
+)
+
+const (
+	// SpecJSONPath is the sub-path the Handler serves the spec as JSON from
+	SpecJSONPath = "openapi.json"
+	// SpecYAMLPath is the sub-path the Handler serves the spec as YAML from
+	SpecYAMLPath = "openapi.yaml"
+)
+
+const (
+	contentTypeHTML = "text/html; charset=utf-8"
+	contentTypeJSON = "application/json"
+	contentTypeYAML = "application/yaml"
+	contentTypeJS   = "text/javascript; charset=utf-8"
+)
+
+// immutableCacheControl lets browsers cache the versioned self-hosted bundle forever
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// bundle is the self-hosted Scalar bundle served by every handler
+var bundle = newContent(contentTypeJS, assets.ScalarBundle)
+
+// handler serves the Scalar UI and the spec it was rendered from
+type handler struct {
+	renderer     *Renderer
+	responses    atomic.Pointer[responses]
+	cacheControl string
+}
+
+// responses holds the contents served for the prepared specs
+type responses struct {
+	state   *renderState
+	options *Options
+	page    *content
+	specs   map[*document]*specContents
+}
+
+// specContents holds the spec of a document in the formats it is served in
+type specContents struct {
+	json *content
+	yaml *content
+}
+
+// content is a response body along with its content type and entity tag
+type content struct {
+	body        []byte
+	contentType string
+	etag        string
+}
+
+// newContent creates content with a strong entity tag computed from the hash of body and related parts
+func newContent(contentType string, body []byte, related ...[]byte) *content {
+	hash := sha256.New()
+	hash.Write(body)
+	for _, part := range related {
+		hash.Write(part)
+	}
+
+	return &content{
+		body:        body,
+		contentType: contentType,
+		etag:        `"` + hex.EncodeToString(hash.Sum(nil)) + `"`,
+	}
+}
+
+// Handler returns an http.Handler serving the Scalar UI at its root and the loaded spec
+// at the SpecJSONPath and SpecYAMLPath sub-paths, other sub-paths are answered with 404 Not Found.
+// The spec is loaded and the page rendered once, so the handler can be mounted under any prefix,
+// e.g. mux.Handle("/docs/", handler)
+//
+// Responses carry ETag, Last-Modified and Cache-Control headers and conditional requests
+// are answered with 304 Not Modified.
+//
+// When SpecURL is configured the spec is not loaded and the sub-paths redirect to SpecURL instead.
+//
+// With WithSources the spec of every source is served under its slug, e.g. <slug>/openapi.json,
+// and the spec of the default source at the root sub-paths.
+//
+// With WithContentSecurityPolicy the page is rendered with a fresh nonce for every request
+// and sent along with the matching Content-Security-Policy header.
+//
+// With WithSelfHosted the embedded Scalar bundle is served at the assets.ScalarBundleName sub-path.
+//
+// With WithRequestHook the page and the specs are customized for every request from copies of the
+// prepared specs and options, see RequestHook.
+//
+// With WithHotReload the page is refreshed through Server-Sent Events streamed from the
+// HotReloadEventsPath sub-path whenever the spec directory changes. The returned handler
+// implements io.Closer to stop watching the directory.
+func Handler(opts ...Option) (http.Handler, error) {
+	renderer, err := NewRenderer(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return renderer.Handler()
+}
+
+// Handler returns an http.Handler serving the page rendered by this Renderer, see Handler
+func (r *Renderer) Handler() (http.Handler, error) {
+	h := &handler{
+		renderer:     r,
+		cacheControl: r.options.CacheControl,
+	}
+	if _, err := h.current(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// current returns the responses for the spec currently prepared by the renderer,
+// they are rebuilt only when the spec was reloaded
+func (h *handler) current() (*responses, error) {
+	state := h.renderer.state.Load()
+	if current := h.responses.Load(); current != nil && current.state == state {
+		return current, nil
+	}
+
+	current, err := h.newResponses(state, h.renderer.options)
+	if err != nil {
+		return nil, err
+	}
+	h.responses.Store(current)
+	return current, nil
+}
+
+// forRequest returns the responses for the specs and options customized by the request hooks, only the
+// page or the spec the request is for is built
+func (h *handler) forRequest(r *http.Request, current *responses, name string) (*responses, error) {
+	state, options, err := h.renderer.forRequest(r, current.state)
+	if err != nil {
+		return nil, err
+	}
+
+	custom := &responses{state: state, options: options, specs: map[*document]*specContents{}}
+	switch name {
+	case SpecJSONPath, SpecYAMLPath:
+		if doc := state.document(specSlug(r)); doc != nil && doc.spec != nil {
+			custom.specs[doc], err = newSpecContents(doc, name == SpecYAMLPath)
+		}
+	default:
+		// the page is rendered with a fresh nonce when sent with a Content-Security-Policy
+		if !h.renderer.options.ContentSecurityPolicy {
+			custom.page, err = h.newPage(state, options)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return custom, nil
+}
+
+// newResponses renders the page and encodes the specs of the state
+func (h *handler) newResponses(state *renderState, options *Options) (*responses, error) {
+	current := &responses{state: state, options: options, specs: map[*document]*specContents{}}
+	for _, doc := range state.documents {
+		if doc.spec == nil {
+			continue
+		}
+
+		contents, err := newSpecContents(doc, true)
+		if err != nil {
+			return nil, err
+		}
+		current.specs[doc] = contents
+	}
+
+	page, err := h.newPage(state, options)
+	if err != nil {
+		return nil, err
+	}
+	current.page = page
+	return current, nil
+}
+
+// newPage renders the page of the state, its entity tag covers the specs embedded in the page
+func (h *handler) newPage(state *renderState, options *Options) (*content, error) {
+	page, err := h.renderer.render(state, options)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := make([][]byte, 0, len(state.documents))
+	for _, doc := range state.documents {
+		if doc.spec != nil {
+			specs = append(specs, doc.specJSON)
+		}
+	}
+	return newContent(contentTypeHTML, []byte(page), specs...), nil
+}
+
+// newSpecContents encodes the loaded spec of the document as JSON, and as YAML when withYAML is set
+func newSpecContents(doc *document, withYAML bool) (*specContents, error) {
+	contents := &specContents{json: newContent(contentTypeJSON, doc.specJSON)}
+	if !withYAML {
+		return contents, nil
+	}
+
+	specYAML, err := yaml.Marshal(doc.spec)
+	if err != nil {
+		return nil, err
+	}
+	contents.yaml = newContent(contentTypeYAML, specYAML)
+	return contents, nil
+}
+
+// Close stops the hot reload of the underlying Renderer, if enabled
+func (h *handler) Close() error {
+	return h.renderer.Close()
+}
+
+// ServeHTTP implements http.Handler
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if path.Base(r.URL.Path) == HotReloadEventsPath && h.renderer.reload != nil {
+		h.renderer.reload.ServeHTTP(w, r)
+		return
+	}
+
+	if path.Base(r.URL.Path) == assets.ScalarBundleName && h.renderer.options.SelfHosted {
+		w.Header().Set("Content-Type", bundle.contentType)
+		w.Header().Set("ETag", bundle.etag)
+		w.Header().Set("Cache-Control", immutableCacheControl)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(bundle.body))
+		return
+	}
+
+	name := path.Base(r.URL.Path)
+	if name != SpecJSONPath && name != SpecYAMLPath && relativePath(r) != "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	h.renderer.refresh(r.Context())
+	current, err := h.current()
+	if err == nil && len(h.renderer.options.RequestHooks) > 0 {
+		current, err = h.forRequest(r, current, name)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch name {
+	case SpecJSONPath:
+		h.serveSpec(w, r, current, func(c *specContents) *content { return c.json })
+	case SpecYAMLPath:
+		h.serveSpec(w, r, current, func(c *specContents) *content { return c.yaml })
+	default:
+		if h.renderer.options.ContentSecurityPolicy {
+			h.servePageWithNonce(w, r, current)
+			return
+		}
+		h.serve(w, r, current, current.page)
+	}
+}
+
+// relativePath returns the path of the request relative to where the handler is mounted, without the leading
+// slash. The path of the pattern the handler is registered with is removed when the prefix was not stripped.
+func relativePath(r *http.Request) string {
+	p := r.URL.Path
+	if rest, ok := trimMount(p, patternPath(r.Pattern)); ok {
+		p = rest
+	}
+	return strings.TrimPrefix(p, "/")
+}
+
+// trimMount removes the segments of the mount path from the start of the path, a {name} wildcard matches
+// any segment and a {name...} wildcard the rest of the path. It reports false when the path is not under the mount.
+func trimMount(p, mount string) (string, bool) {
+	rest := p
+	for _, segment := range strings.Split(strings.Trim(mount, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "...}") {
+			return "", true
+		}
+
+		current, after, found := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+		wildcard := strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+		if !wildcard && current != segment {
+			return p, false
+		}
+		rest = ""
+		if found {
+			rest = "/" + after
+		}
+	}
+	return rest, true
+}
+
+// patternPath returns the path of a http.ServeMux pattern, e.g. /docs/ for "GET example.com/docs/"
+func patternPath(pattern string) string {
+	if _, rest, ok := strings.Cut(pattern, " "); ok {
+		pattern = rest
+	}
+	i := strings.Index(pattern, "/")
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSuffix(pattern[i:], "{$}")
+}
+
+// servePageWithNonce renders the page with a fresh nonce and sends the matching Content-Security-Policy,
+// the page is never cached as the nonce must not be reused
+func (h *handler) servePageWithNonce(w http.ResponseWriter, r *http.Request, current *responses) {
+	nonce, err := NewNonce()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	page, err := h.renderer.render(current.state, current.options, WithNonce(nonce))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeHTML)
+	w.Header().Set("Content-Security-Policy", contentSecurityPolicy(current.state, current.options, nonce))
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeContent(w, r, "", time.Time{}, strings.NewReader(page))
+}
+
+// serveSpec serves the spec of the document identified by the parent path segment, or of the default
+// document when there is none, 404 Not Found when no document has the slug. It redirects to SpecURL
+// when the spec was not loaded.
+func (h *handler) serveSpec(w http.ResponseWriter, r *http.Request, current *responses, format func(*specContents) *content) {
+	doc := current.state.document(specSlug(r))
+	if doc == nil {
+		http.NotFound(w, r)
+		return
+	}
+	contents, ok := current.specs[doc]
+	if !ok {
+		http.Redirect(w, r, doc.remoteURL(), http.StatusFound)
+		return
+	}
+	h.serve(w, r, current, format(contents))
+}
+
+// specSlug returns the slug of the document a spec is requested for, the parent path segment of the
+// request or empty for the default document
+func specSlug(r *http.Request) string {
+	slug := path.Dir(relativePath(r))
+	if slug == "." {
+		return ""
+	}
+	return slug
+}
+
+// serve writes the content with its validators, http.ServeContent takes care of
+// conditional requests and omits the body for HEAD requests
+func (h *handler) serve(w http.ResponseWriter, r *http.Request, current *responses, c *content) {
+	w.Header().Set("Content-Type", c.contentType)
+	w.Header().Set("ETag", c.etag)
+	if h.cacheControl != "" {
+		w.Header().Set("Cache-Control", h.cacheControl)
+	}
+	http.ServeContent(w, r, "", current.state.lastModified, bytes.NewReader(c.body))
+}
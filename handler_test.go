@@ -0,0 +1,267 @@
+package scalargo_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	scalargo "github.com/bdpiprava/scalar-go"
+	"github.com/bdpiprava/scalar-go/assets"
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+func Test_Handler(t *testing.T) {
+	h, err := scalargo.Handler(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+	)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle("/docs/", http.StripPrefix("/docs", h))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	testCases := []struct {
+		name            string
+		method          string
+		path            string
+		wantStatus      int
+		wantContentType string
+		asserter        func(t *testing.T, body []byte)
+	}{
+		{
+			name:            "should serve html at the root",
+			method:          http.MethodGet,
+			path:            "/docs/",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/html; charset=utf-8",
+			asserter: func(t *testing.T, body []byte) {
+				require.Equal(t, "Swagger Petstore", parseContent(string(body)).title)
+			},
+		},
+		{
+			name:            "should serve spec as json",
+			method:          http.MethodGet,
+			path:            "/docs/openapi.json",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			asserter: func(t *testing.T, body []byte) {
+				var spec model.Spec
+				require.NoError(t, json.Unmarshal(body, &spec))
+				require.Equal(t, "Swagger Petstore", spec.Info.Title)
+				require.Len(t, spec.Paths, 2)
+			},
+		},
+		{
+			name:            "should serve spec as yaml",
+			method:          http.MethodGet,
+			path:            "/docs/openapi.yaml",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/yaml",
+			asserter: func(t *testing.T, body []byte) {
+				var spec model.Spec
+				require.NoError(t, yaml.Unmarshal(body, &spec))
+				require.Equal(t, "Swagger Petstore", spec.Info.Title)
+				require.Len(t, spec.Paths, 2)
+			},
+		},
+		{
+			name:            "should not write body for HEAD request",
+			method:          http.MethodHead,
+			path:            "/docs/openapi.json",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			asserter:        func(t *testing.T, body []byte) { require.Empty(t, body) },
+		},
+		{
+			name:       "should not serve the page at another sub-path",
+			method:     http.MethodGet,
+			path:       "/docs/favicon.ico",
+			wantStatus: http.StatusNotFound,
+			asserter:   func(t *testing.T, _ []byte) {},
+		},
+		{
+			name:       "should not serve the page at a nested sub-path",
+			method:     http.MethodGet,
+			path:       "/docs/anything/else",
+			wantStatus: http.StatusNotFound,
+			asserter:   func(t *testing.T, _ []byte) {},
+		},
+		{
+			name:       "should reject unsupported method",
+			method:     http.MethodPost,
+			path:       "/docs/",
+			wantStatus: http.StatusMethodNotAllowed,
+			asserter:   func(t *testing.T, _ []byte) {},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), tc.method, server.URL+tc.path, nil)
+			require.NoError(t, err)
+
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.wantStatus, res.StatusCode)
+			if tc.wantContentType != "" {
+				require.Equal(t, tc.wantContentType, res.Header.Get("Content-Type"))
+			}
+			if tc.wantStatus == http.StatusMethodNotAllowed {
+				require.Equal(t, "GET, HEAD", res.Header.Get("Allow"))
+			}
+			tc.asserter(t, body)
+		})
+	}
+}
+
+func Test_Handler_MountedWithoutStrippingPrefix(t *testing.T) {
+	h, err := scalargo.Handler(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+	)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle("/docs/", h)
+	mux.Handle("/t/{tenant}/docs/", h)
+
+	testCases := []struct {
+		path       string
+		wantStatus int
+	}{
+		{path: "/docs/", wantStatus: http.StatusOK},
+		{path: "/docs/openapi.json", wantStatus: http.StatusOK},
+		{path: "/docs/favicon.ico", wantStatus: http.StatusNotFound},
+		{path: "/docs/nope/openapi.json", wantStatus: http.StatusNotFound},
+		{path: "/t/acme/docs/", wantStatus: http.StatusOK},
+		{path: "/t/acme/docs/openapi.json", wantStatus: http.StatusOK},
+		{path: "/t/acme/docs/openapi.yaml", wantStatus: http.StatusOK},
+		{path: "/t/acme/docs/favicon.ico", wantStatus: http.StatusNotFound},
+		{path: "/t/acme/docs/nope/openapi.json", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			require.Equal(t, tc.wantStatus, rec.Code)
+		})
+	}
+}
+
+func Test_Handler_RedirectsToSpecURL(t *testing.T) {
+	const specURL = "https://cdn.jsdelivr.net/npm/@scalar/galaxy/dist/latest.yaml"
+	h, err := scalargo.Handler(scalargo.WithSpecURL(specURL))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	require.Equal(t, http.StatusFound, rec.Code)
+	require.Equal(t, specURL, rec.Header().Get("Location"))
+}
+
+func Test_Handler_ReturnsError_WhenNoSpecConfigured(t *testing.T) {
+	h, err := scalargo.Handler()
+
+	require.ErrorContains(t, err, "one of SpecURL, SpecDirectory or SpecBytes must be configured")
+	require.Nil(t, h)
+}
+
+func Test_Handler_ConditionalGet(t *testing.T) {
+	h, err := scalargo.Handler(
+		scalargo.WithSpecDir("./data/loader-multiple-files"),
+		scalargo.WithBaseFileName("api.yml"),
+	)
+	require.NoError(t, err)
+
+	for _, target := range []string{"/", "/openapi.json", "/openapi.yaml"} {
+		t.Run(target, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			etag := rec.Header().Get("ETag")
+			require.NotEmpty(t, etag)
+			require.NotEmpty(t, rec.Header().Get("Last-Modified"))
+			require.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			req.Header.Set("If-None-Match", etag)
+			rec = httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusNotModified, rec.Code)
+			require.Empty(t, rec.Body.Bytes())
+		})
+	}
+}
+
+func Test_Handler_UsesLastModifiedOfSpecFiles(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	specFile := filepath.Join(dir, "api.yaml")
+	require.NoError(t, os.WriteFile(specFile, []byte("openapi: 3.0.0\ninfo:\n  title: Test\n"), 0o600))
+	require.NoError(t, os.Chtimes(specFile, modTime, modTime))
+
+	h, err := scalargo.Handler(scalargo.WithSpecDir(dir), scalargo.WithCacheControl("public, max-age=60"))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, modTime.Format(http.TimeFormat), rec.Header().Get("Last-Modified"))
+	require.Equal(t, "public, max-age=60", rec.Header().Get("Cache-Control"))
+}
+
+func Test_Handler_SelfHosted(t *testing.T) {
+	h, err := scalargo.Handler(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+		scalargo.WithSelfHosted("/docs/"),
+	)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Contains(t, rec.Body.String(), `<script src="/docs/`+assets.ScalarBundleName+`" integrity="sha384-`)
+	require.NotContains(t, rec.Body.String(), scalargo.DefaultCDN)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+assets.ScalarBundleName, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/javascript; charset=utf-8", rec.Header().Get("Content-Type"))
+	require.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+	require.Equal(t, assets.ScalarBundle, rec.Body.Bytes())
+}
+
+func Test_Handler_SelfHosted_ShouldPreferConfiguredCDN(t *testing.T) {
+	const cdn = "https://assets.example.com/scalar.js"
+	h, err := scalargo.Handler(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+		scalargo.WithCDN(cdn),
+		scalargo.WithSelfHosted(""),
+	)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Contains(t, rec.Body.String(), `<script src="`+cdn+`"></script>`)
+}
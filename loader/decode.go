@@ -0,0 +1,91 @@
+package loader
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+// decodeYaml unmarshalls the YAML content read from file and returns it with the order of its keys,
+// every problem is reported as an Error
+func decodeYaml[T any](file string, content []byte) (T, model.KeyOrder, error) {
+	var data T
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return data, nil, yamlError(file, nil, err)
+	}
+	if root.Kind == 0 {
+		return data, model.KeyOrder{}, nil
+	}
+
+	if err := root.Decode(&data); err != nil {
+		return data, nil, yamlError(file, &root, err)
+	}
+
+	order := model.KeyOrder{}
+	keyOrder(&root, "", order)
+	return data, order, nil
+}
+
+// decodeJSON unmarshalls the JSON content read from file and returns it with the order of its keys,
+// a problem is reported as an Error
+func decodeJSON[T any](file string, content []byte) (T, model.KeyOrder, error) {
+	var data T
+	err := json.Unmarshal(content, &data)
+	if err == nil {
+		// JSON is YAML, so the order of the keys is read from the YAML nodes
+		order := model.KeyOrder{}
+		var root yaml.Node
+		if yaml.Unmarshal(content, &root) == nil {
+			keyOrder(&root, "", order)
+		}
+		return data, order, nil
+	}
+
+	e := &Error{File: file, Err: err}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		e.Line, e.Column = position(content, syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		e.Line, e.Column = position(content, typeErr.Offset)
+		if typeErr.Field != "" {
+			tokens := strings.Split(typeErr.Field, ".")
+			for i, token := range tokens {
+				tokens[i] = model.EscapeToken(token)
+			}
+			e.Pointer = "/" + strings.Join(tokens, "/")
+		}
+	}
+	return data, nil, e
+}
+
+// keyOrder records the order of the keys of every mapping within the node, pointer is the location of the node
+func keyOrder(node *yaml.Node, pointer string, order model.KeyOrder) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			keyOrder(child, pointer, order)
+		}
+	case yaml.AliasNode:
+		keyOrder(node.Alias, pointer, order)
+	case yaml.MappingNode:
+		keys := make([]string, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			keys = append(keys, key)
+			keyOrder(node.Content[i+1], pointer+"/"+model.EscapeToken(key), order)
+		}
+		order[pointer] = keys
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			keyOrder(item, pointer+"/"+strconv.Itoa(i), order)
+		}
+	}
+}
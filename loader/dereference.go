@@ -0,0 +1,157 @@
+package loader
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+// keyCircular marks a reference kept because its target contains itself
+const keyCircular = "x-circular"
+
+// dereferencer replaces the local references of a spec with their targets
+type dereferencer struct {
+	root model.GenericObject
+	// expanding holds the JSON pointers of the targets being inlined, a reference to one of them is recursive
+	expanding map[string]bool
+	// order is the key order of the copy, the order of a target in source is copied to where it is inlined
+	order  model.KeyOrder
+	source model.KeyOrder
+}
+
+// Dereference returns a deep copy of the spec with every local reference (#/...) in the paths, webhooks and
+// components replaced by its target. A recursive reference is kept at the point where the target
+// would contain itself and marked with "x-circular": true. The spec is left untouched.
+func Dereference(spec *model.Spec) (*model.Spec, error) {
+	dereferenced := *spec
+	dereferenced.Servers = slices.Clone(spec.Servers)
+	dereferenced.Tags = slices.Clone(spec.Tags)
+	dereferenced.TagsGroup = slices.Clone(spec.TagsGroup)
+	dereferenced.KeyOrder = maps.Clone(spec.KeyOrder)
+	if dereferenced.KeyOrder == nil {
+		dereferenced.KeyOrder = model.KeyOrder{}
+	}
+
+	components := model.GenericObject{}
+	for name, section := range spec.Components.Sections() {
+		if *section != nil {
+			components[name] = *section
+		}
+	}
+
+	d := &dereferencer{
+		root:      model.GenericObject{"paths": spec.Paths, "webhooks": spec.Webhooks, "components": components},
+		expanding: map[string]bool{},
+		order:     dereferenced.KeyOrder,
+		source:    spec.KeyOrder,
+	}
+
+	paths, err := d.dereferenceSection(spec.Paths, "/paths", false)
+	if err != nil {
+		return nil, err
+	}
+	dereferenced.Paths = paths
+
+	if dereferenced.Webhooks, err = d.dereferenceSection(spec.Webhooks, "/webhooks", false); err != nil {
+		return nil, err
+	}
+
+	for name, section := range dereferenced.Components.Sections() {
+		if *section, err = d.dereferenceSection(*section, "/components/"+name, true); err != nil {
+			return nil, err
+		}
+	}
+	return &dereferenced, nil
+}
+
+// dereferenceSection returns a copy of the section with the references replaced, the entries of
+// a components section are treated as being inlined, so they keep references to themselves
+func (d *dereferencer) dereferenceSection(section model.GenericObject, pointer string, components bool) (model.GenericObject, error) {
+	if section == nil {
+		return nil, nil
+	}
+
+	res := make(model.GenericObject, len(section))
+	for _, key := range sortedKeys(section) {
+		entryPointer := pointer + "/" + model.EscapeToken(key)
+		if components {
+			d.expanding[entryPointer] = true
+		}
+		value, err := d.dereference(section[key], entryPointer)
+		delete(d.expanding, entryPointer)
+		if err != nil {
+			return nil, err
+		}
+		res[key] = value
+	}
+	return res, nil
+}
+
+// dereference returns a copy of the value with the references replaced, pointer is its location in the spec
+func (d *dereferencer) dereference(value any, pointer string) (any, error) {
+	switch v := normalizeMap(value).(type) {
+	case model.GenericObject:
+		if ref, ok := v[keyRef].(string); ok && strings.HasPrefix(ref, "#") {
+			return d.dereferenceRef(v, ref, pointer)
+		}
+
+		res := make(model.GenericObject, len(v))
+		for key, item := range v {
+			dereferenced, err := d.dereference(item, pointer+"/"+model.EscapeToken(key))
+			if err != nil {
+				return nil, err
+			}
+			res[key] = dereferenced
+		}
+		return res, nil
+	case []any:
+		res := make([]any, len(v))
+		for i, item := range v {
+			dereferenced, err := d.dereference(item, fmt.Sprintf("%s/%d", pointer, i))
+			if err != nil {
+				return nil, err
+			}
+			res[i] = dereferenced
+		}
+		return res, nil
+	default:
+		return value, nil
+	}
+}
+
+// dereferenceRef returns the target of the local reference object with its own references replaced
+func (d *dereferencer) dereferenceRef(obj model.GenericObject, ref string, pointer string) (any, error) {
+	fragment := strings.TrimPrefix(ref, "#")
+	target, err := url.PathUnescape(fragment)
+	if err != nil {
+		target = fragment
+	}
+
+	siblings := normalize(obj).(model.GenericObject)
+	if d.expanding[target] {
+		siblings[keyCircular] = true
+		return siblings, nil
+	}
+
+	value, err := lookup(d.root, fragment)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve $ref '%s' at '%s': %w", ref, pointer, err)
+	}
+
+	d.expanding[target] = true
+	defer delete(d.expanding, target)
+	d.order.Copy(pointer, d.source, target)
+
+	resolved, err := d.dereference(value, pointer)
+	if err != nil {
+		return nil, err
+	}
+	if resolvedObj, ok := resolved.(model.GenericObject); ok {
+		return withSiblings(resolvedObj, siblings), nil
+	}
+	return resolved, nil
+}
@@ -0,0 +1,109 @@
+package loader_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bdpiprava/scalar-go/loader"
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+func Test_Dereference(t *testing.T) {
+	spec, err := loader.LoadFromDir("../data/loader", "pet-store.yml")
+	require.NoError(t, err)
+
+	dereferenced, err := loader.Dereference(spec)
+	require.NoError(t, err)
+
+	getPets := dereferenced.Paths["/pets"].(model.GenericObject)["get"].(model.GenericObject)
+	responses := getPets["responses"].(model.GenericObject)
+	require.Equal(t, spec.Components.Responses["Error"].(model.GenericObject)["description"],
+		responses["default"].(model.GenericObject)["description"])
+
+	petsSchema := responses["200"].(model.GenericObject)["content"].(model.GenericObject)["application/json"].(model.GenericObject)["schema"].(model.GenericObject)
+	require.Equal(t, "array", petsSchema["type"])
+	require.Equal(t, "object", petsSchema["items"].(model.GenericObject)["type"])
+
+	require.NotContains(t, dereferenced.Components.Schemas["Pets"], "$ref")
+
+	// the spec is left untouched
+	require.Equal(t, model.GenericObject{"$ref": "#/components/responses/Error"},
+		spec.Paths["/pets"].(model.GenericObject)["get"].(model.GenericObject)["responses"].(model.GenericObject)["default"])
+}
+
+func Test_Dereference_Recursive(t *testing.T) {
+	spec, err := loader.LoadFromDir("../data/refs/api", "api.yaml", loader.WithRefMode(loader.BundleRefs))
+	require.NoError(t, err)
+
+	dereferenced, err := loader.Dereference(spec)
+	require.NoError(t, err)
+
+	require.Equal(t, model.GenericObject{"$ref": "#/components/schemas/Tree", "x-circular": true},
+		dereferenced.Components.Schemas["Tree"].(model.GenericObject)["properties"].(model.GenericObject)["children"].(model.GenericObject)["items"])
+
+	require.Equal(t, model.GenericObject{
+		"type": "object",
+		"properties": model.GenericObject{
+			"next": model.GenericObject{
+				"type": "object",
+				"properties": model.GenericObject{
+					"node": model.GenericObject{"$ref": "#/components/schemas/Node", "x-circular": true},
+				},
+			},
+		},
+	}, dereferenced.Components.Schemas["Node"])
+
+	getPets := dereferenced.Paths["/pets"].(model.GenericObject)["get"].(model.GenericObject)
+	require.Equal(t, "limit", getPets["parameters"].([]any)[0].(model.GenericObject)["name"])
+}
+
+func Test_Dereference_Webhooks(t *testing.T) {
+	spec := &model.Spec{
+		Webhooks: model.GenericObject{
+			"newPet": model.GenericObject{"$ref": "#/components/pathItems/NewPet"},
+		},
+		Components: model.Components{
+			PathItems: model.GenericObject{
+				"NewPet": model.GenericObject{
+					"post": model.GenericObject{"requestBody": model.GenericObject{"$ref": "#/components/requestBodies/Pet"}},
+				},
+			},
+			RequestBodies: model.GenericObject{
+				"Pet": model.GenericObject{"description": "the new pet"},
+			},
+		},
+	}
+
+	dereferenced, err := loader.Dereference(spec)
+	require.NoError(t, err)
+
+	require.Equal(t, model.GenericObject{
+		"post": model.GenericObject{"requestBody": model.GenericObject{"description": "the new pet"}},
+	}, dereferenced.Webhooks["newPet"])
+
+	// the webhooks are copied, the spec is left untouched
+	dereferenced.Webhooks["newPet"].(model.GenericObject)["post"] = nil
+	require.Equal(t, model.GenericObject{"$ref": "#/components/pathItems/NewPet"}, spec.Webhooks["newPet"])
+}
+
+func Test_Dereference_Unresolvable(t *testing.T) {
+	spec := &model.Spec{
+		Paths: model.GenericObject{
+			"/pets": model.GenericObject{"$ref": "#/components/pathItems/Missing"},
+		},
+	}
+
+	dereferenced, err := loader.Dereference(spec)
+
+	require.EqualError(t, err, "cannot resolve $ref '#/components/pathItems/Missing' at '/paths/~1pets': JSON pointer '/components/pathItems/Missing' not found")
+	require.Nil(t, dereferenced)
+}
+
+func Test_Load_DereferenceRefs(t *testing.T) {
+	spec, err := loader.LoadFromDir("../data/refs/api", "api.yaml", loader.WithRefMode(loader.DereferenceRefs))
+	require.NoError(t, err)
+
+	getPets := spec.Paths["/pets"].(model.GenericObject)["get"].(model.GenericObject)
+	require.Equal(t, "unexpected error", getPets["responses"].(model.GenericObject)["default"].(model.GenericObject)["description"])
+}
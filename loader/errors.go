@@ -0,0 +1,152 @@
+package loader
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+// Error is a problem found while loading a spec, located in the file it was found in.
+// Several problems are aggregated with errors.Join, use errors.As to get the first one.
+type Error struct {
+	// File is the path of the file, empty when the spec is loaded from bytes
+	File string
+	// Line and Column are the position of the problem in the file starting at 1, zero when unknown
+	Line   int
+	Column int
+	// Pointer is the JSON pointer of the failing element in the file, or in the spec for the entries merged
+	// from several files and the references, empty when unknown
+	Pointer string
+	// Err is the cause of the problem
+	Err error
+}
+
+// Error returns the location of the problem followed by its cause, e.g. api.yaml:12:7 at '/info/title': ...
+func (e *Error) Error() string {
+	location := e.location()
+	if e.Pointer != "" {
+		location = strings.TrimSpace(location + " at '" + e.Pointer + "'")
+	}
+	if location == "" {
+		return e.Err.Error()
+	}
+	return location + ": " + e.Err.Error()
+}
+
+// Unwrap returns the cause of the problem
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// location returns the file and position of the problem, empty when both are unknown
+func (e *Error) location() string {
+	position := ""
+	if e.Line > 0 {
+		position = strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			position += ":" + strconv.Itoa(e.Column)
+		}
+	}
+
+	switch {
+	case position == "":
+		return e.File
+	case e.File == "":
+		return "line " + position
+	default:
+		return e.File + ":" + position
+	}
+}
+
+// errUnsupportedFile returns the error of a file with none of the extensions
+func errUnsupportedFile(file string, kind string, extensions string) error {
+	return &Error{File: file, Err: errors.New("not a " + kind + " file, supported extensions are [" + extensions + "]")}
+}
+
+// yamlLine matches the position yaml.v3 prefixes its messages with
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// yamlError returns the errors of yaml.v3 located in the file, the JSON pointer is found in the parsed root when there is one
+func yamlError(file string, root *yaml.Node, err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return yamlMessageError(file, root, err.Error())
+	}
+
+	errs := make([]error, 0, len(typeErr.Errors))
+	for _, message := range typeErr.Errors {
+		errs = append(errs, yamlMessageError(file, root, message))
+	}
+	return errors.Join(errs...)
+}
+
+// yamlMessageError returns the Error of a yaml.v3 message
+func yamlMessageError(file string, root *yaml.Node, message string) *Error {
+	e := &Error{File: file}
+	if match := yamlLine.FindStringSubmatch(message); match != nil {
+		e.Line, _ = strconv.Atoi(match[1])
+		message = match[2]
+		if root != nil {
+			e.Pointer, e.Column, _ = locate(root, "", e.Line)
+		}
+	}
+	e.Err = errors.New(strings.TrimPrefix(message, "yaml: "))
+	return e
+}
+
+// locate returns the JSON pointer and column of the element of the node found on the line, the entries
+// of block collections are located rather than the collections themselves
+func locate(node *yaml.Node, pointer string, line int) (string, int, bool) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if at, column, ok := locate(child, pointer, line); ok {
+				return at, column, ok
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPointer := pointer + "/" + model.EscapeToken(key.Value)
+			if value.Line == line && !isBlockCollection(value) {
+				return keyPointer, value.Column, true
+			}
+			if at, column, ok := locate(value, keyPointer, line); ok {
+				return at, column, ok
+			}
+			if key.Line == line {
+				return keyPointer, key.Column, true
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			itemPointer := pointer + "/" + strconv.Itoa(i)
+			if item.Line == line && !isBlockCollection(item) {
+				return itemPointer, item.Column, true
+			}
+			if at, column, ok := locate(item, itemPointer, line); ok {
+				return at, column, ok
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// isBlockCollection checks if the node is a mapping or a sequence in block style
+func isBlockCollection(node *yaml.Node) bool {
+	return (node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode) && node.Style&yaml.FlowStyle == 0
+}
+
+// position returns the line and column of the byte offset in the content
+func position(content []byte, offset int64) (int, int) {
+	offset = min(max(offset, 0), int64(len(content)))
+	before := content[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	return line, max(len(before)-(bytes.LastIndexByte(before, '\n')+1), 1)
+}
@@ -0,0 +1,87 @@
+package loader
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// reader reads the files of a spec from the OS filesystem, or from fsys when set,
+// and records every file read in files
+type reader struct {
+	fsys  fs.FS
+	files Files
+}
+
+// newReader returns a reader of fsys, or of the OS filesystem when fsys is nil
+func newReader(fsys fs.FS) *reader {
+	return &reader{fsys: fsys, files: Files{}}
+}
+
+// read reads the file and records its modification time
+func (r *reader) read(name string) ([]byte, error) {
+	info, err := r.stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var contentBytes []byte
+	if r.fsys == nil {
+		contentBytes, err = os.ReadFile(name)
+	} else {
+		contentBytes, err = fs.ReadFile(r.fsys, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.files[name] = info.ModTime()
+	return contentBytes, nil
+}
+
+// readDir reads the entries of the directory
+func (r *reader) readDir(name string) ([]fs.DirEntry, error) {
+	if r.fsys == nil {
+		return os.ReadDir(name)
+	}
+	return fs.ReadDir(r.fsys, name)
+}
+
+// stat returns the fs.FileInfo of the file
+func (r *reader) stat(name string) (fs.FileInfo, error) {
+	if r.fsys == nil {
+		return os.Stat(name)
+	}
+	return fs.Stat(r.fsys, name)
+}
+
+// exists reports whether the file exists
+func (r *reader) exists(name string) bool {
+	_, err := r.stat(name)
+	return err == nil
+}
+
+// join joins the path elements, an fs.FS always uses forward slashes
+func (r *reader) join(elem ...string) string {
+	if r.fsys == nil {
+		return filepath.Join(elem...)
+	}
+	return path.Join(elem...)
+}
+
+// dir returns the directory of the file
+func (r *reader) dir(name string) string {
+	if r.fsys == nil {
+		return filepath.Dir(name)
+	}
+	return path.Dir(name)
+}
+
+// clean returns the shortest path equivalent to name
+func (r *reader) clean(name string) string {
+	if r.fsys == nil {
+		return filepath.Clean(name)
+	}
+	return path.Clean(name)
+}
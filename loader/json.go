@@ -1,30 +1,25 @@
 package loader
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
 	"strings"
+
+	"github.com/bdpiprava/scalar-go/model"
 )
 
-// readJSONFile reads a JSON file and unmarshalls it into the provided data structure.
-func readJSONFile[T any](path string) (T, error) {
+// readJSONFile reads a JSON file and unmarshalls it into the provided data structure,
+// the order of its keys is returned along with it.
+func readJSONFile[T any](path string, r *reader) (T, model.KeyOrder, error) {
 	var data T
 	if !isJSONFile(path) {
-		return data, fmt.Errorf("file '%s' is not a JSON file, supported extensions are [JSON]", path)
-	}
-
-	contentBytes, err := os.ReadFile(path)
-	if err != nil {
-		return data, err
+		return data, nil, errUnsupportedFile(path, "JSON", "json")
 	}
 
-	err = json.Unmarshal(contentBytes, &data)
+	contentBytes, err := r.read(path)
 	if err != nil {
-		return data, err
+		return data, nil, err
 	}
 
-	return data, err
+	return decodeJSON[T](path, contentBytes)
 }
 
 // isJSONFile checks if the file is a JSON file.
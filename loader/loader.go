@@ -1,49 +1,111 @@
 package loader
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"maps"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"strings"
+	"time"
 
 	"github.com/bdpiprava/scalar-go/model"
 	"github.com/bdpiprava/scalar-go/sanitizer"
-	"gopkg.in/yaml.v3"
 )
 
+// Files holds the path and modification time of every file read while loading a spec
+type Files map[string]time.Time
+
+// LastModified returns the latest modification time of the files, zero when there are none
+func (f Files) LastModified() time.Time {
+	var lastModified time.Time
+	for _, modTime := range f {
+		if modTime.After(lastModified) {
+			lastModified = modTime
+		}
+	}
+	return lastModified
+}
+
 // LoadFromDir reads the API specification from the provided root directory
-func LoadFromDir(rootDir string, apiFileName string) (*model.Spec, error) {
-	content, err := readFile[model.Spec](filepath.Join(rootDir, apiFileName))
+func LoadFromDir(rootDir string, apiFileName string, opts ...Option) (*model.Spec, error) {
+	spec, _, err := LoadFromDirWithFiles(rootDir, apiFileName, opts...)
+	return spec, err
+}
+
+// LoadFromDirWithFiles reads the API specification from the provided root directory
+// and returns it along with the files that were read. Relative file references ($ref)
+// are followed from any file and resolved as set by WithRefMode, every referenced file is read once.
+func LoadFromDirWithFiles(rootDir string, apiFileName string, opts ...Option) (*model.Spec, Files, error) {
+	return load(newReader(nil), rootDir, apiFileName, opts...)
+}
+
+// LoadFromFS reads the API specification from the root directory of fsys, e.g. an embed.FS
+func LoadFromFS(fsys fs.FS, root, baseFile string, opts ...Option) (*model.Spec, error) {
+	spec, _, err := LoadFromFSWithFiles(fsys, root, baseFile, opts...)
+	return spec, err
+}
+
+// LoadFromFSWithFiles reads the API specification from the root directory of fsys
+// and returns it along with the files that were read, see LoadFromDirWithFiles
+func LoadFromFSWithFiles(fsys fs.FS, root, baseFile string, opts ...Option) (*model.Spec, Files, error) {
+	return load(newReader(fsys), root, baseFile, opts...)
+}
+
+// load reads the API specification from the root directory with the reader
+func load(r *reader, rootDir string, apiFileName string, opts ...Option) (*model.Spec, Files, error) {
+	options := buildOptions(opts...)
+	rootFile := r.join(rootDir, apiFileName)
+	content, order, err := readFile[model.Spec](rootFile, r)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	specContent := &content
+	specContent.KeyOrder = specKeyOrder(order)
 	specContent.Paths = initializeIfNil(specContent.Paths)
 	specContent.Components.Schemas = initializeIfNil(specContent.Components.Schemas)
 	specContent.Components.Parameters = initializeIfNil(specContent.Components.Parameters)
 	specContent.Components.Responses = initializeIfNil(specContent.Components.Responses)
 
-	paths, err := readDirRecursively(filepath.Join(rootDir, "paths"), "paths")
-	if err != nil {
-		return nil, err
+	merger := newMerger(options.mergePolicy, rootFile, specContent.KeyOrder)
+	sections := specContent.Components.Sections()
+	sections[keyPaths] = &specContent.Paths
+
+	// every directory is read, so all the problems of the files are reported at once
+	var errs []error
+	for _, directory := range options.directories() {
+		section, ok := sections[directory.section]
+		if !ok {
+			return nil, nil, fmt.Errorf("directory '%s' is mapped to unknown section '%s'", directory.dir, directory.section)
+		}
+
+		wasNil := *section == nil
+		if wasNil {
+			*section = model.GenericObject{}
+		}
+		err := readDirRecursively(r.join(rootDir, directory.dir), directory.section, sectionPointer(directory.section), r, merger, *section)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if wasNil && len(*section) == 0 {
+			*section = nil
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
 	}
-	maps.Copy(specContent.Paths, *paths)
 
-	responses, err := readDirRecursively(filepath.Join(rootDir, "responses"), "responses")
-	if err != nil {
-		return nil, err
+	resolver := newRefResolver(rootFile, r, options.refMode)
+	if err := resolver.resolveSpec(specContent, merger.sources); err != nil {
+		return nil, nil, err
 	}
-	maps.Copy(specContent.Components.Responses, *responses)
 
-	schemas, err := readDirRecursively(filepath.Join(rootDir, "schemas"), "schemas")
-	if err != nil {
-		return nil, err
+	if options.refMode == DereferenceRefs {
+		if specContent, err = Dereference(specContent); err != nil {
+			return nil, nil, err
+		}
 	}
-	maps.Copy(specContent.Components.Schemas, *schemas)
 
-	return sanitizer.Sanitize(specContent), nil
+	return sanitizer.Sanitize(specContent), r.files, nil
 }
 
 // Load reads the API specification from the provided root directory
@@ -61,23 +123,24 @@ func LoadFromDirRoot(rootDir string) (*model.Spec, error) {
 	return LoadFromDir(rootDir, "api.yaml")
 }
 
-// LoadFromBytes reads the API specification from the provided bytes in either YAML or JSON format
+// LoadFromBytes reads the API specification from the provided bytes in either YAML or JSON format,
+// when both fail the returned error holds the problems found by each
 func LoadFromBytes(bytes []byte) (*model.Spec, error) {
-	specContent := &model.Spec{}
-
 	// Try YAML first
-	err := yaml.Unmarshal(bytes, specContent)
-	if err == nil {
-		return sanitizer.Sanitize(specContent), nil
+	specContent, order, yamlErr := decodeYaml[model.Spec]("", bytes)
+	if yamlErr == nil {
+		specContent.KeyOrder = specKeyOrder(order)
+		return sanitizer.Sanitize(&specContent), nil
 	}
 
 	// Try JSON if YAML fails
-	err = json.Unmarshal(bytes, specContent)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse as YAML or JSON: %w", err)
+	specContent, order, jsonErr := decodeJSON[model.Spec]("", bytes)
+	if jsonErr != nil {
+		return nil, fmt.Errorf("failed to parse as YAML or JSON: %w", errors.Join(yamlErr, jsonErr))
 	}
 
-	return sanitizer.Sanitize(specContent), nil
+	specContent.KeyOrder = specKeyOrder(order)
+	return sanitizer.Sanitize(&specContent), nil
 }
 
 func initializeIfNil(obj model.GenericObject) model.GenericObject {
@@ -87,17 +150,30 @@ func initializeIfNil(obj model.GenericObject) model.GenericObject {
 	return model.GenericObject{}
 }
 
-func exists(path string) bool {
-	_, err := os.Stat(path)
-	return err == nil
+// readFile reads a YAML or JSON file by its extension and unmarshalls it into the provided data structure,
+// the order of its keys is returned along with it.
+func readFile[T any](path string, r *reader) (data T, order model.KeyOrder, err error) {
+	switch {
+	case isYamlFile(path):
+		return readYamlFile[T](path, r)
+	case isJSONFile(path):
+		return readJSONFile[T](path, r)
+	}
+	return data, nil, errUnsupportedFile(path, "YAML or JSON", "yml|yaml|json")
 }
 
-// readFile reads a file and unmarshalls it into the provided data structure.
-func readFile[T any](path string) (data T, err error) {
-	if data, err = readYamlFile[T](path); err == nil {
-		return
-	} else if data, err = readJSONFile[T](path); err == nil {
-		return
+// specKeyOrder returns the key order of the untyped objects of the spec: the paths, webhooks, components
+// and extensions. The typed objects are written in the order of the OpenAPI specification.
+func specKeyOrder(order model.KeyOrder) model.KeyOrder {
+	res := model.KeyOrder{}
+	for pointer, keys := range order {
+		untyped := strings.HasPrefix(pointer, "/components/") || strings.Contains(pointer, "/x-")
+		for _, section := range []string{sectionPointer(keyPaths), "/webhooks"} {
+			untyped = untyped || pointer == section || strings.HasPrefix(pointer, section+"/")
+		}
+		if untyped {
+			res[pointer] = keys
+		}
 	}
-	return data, fmt.Errorf("file '%s' is not a YAML or JSON file, supported extensions are [yml|yaml|json]", path)
+	return res
 }
@@ -1,13 +1,20 @@
 package loader_test
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"reflect"
 	"sort"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 
+	"github.com/bdpiprava/scalar-go/data"
 	"github.com/bdpiprava/scalar-go/loader"
 	"github.com/bdpiprava/scalar-go/model"
 )
@@ -32,8 +39,12 @@ func Test_Load_JsonFile(t *testing.T) {
 func Test_Load_InvalidFileType(t *testing.T) {
 	spec, err := loader.LoadFromDir("../data/loader", "pet-store.xyz")
 
-	require.ErrorContains(t, err, `file '../data/loader/pet-store.xyz' is not a YAML or JSON file, supported extensions are [yml|yaml|json]`)
+	require.ErrorContains(t, err, `../data/loader/pet-store.xyz: not a YAML or JSON file, supported extensions are [yml|yaml|json]`)
 	require.Nil(t, spec)
+
+	var loadErr *loader.Error
+	require.ErrorAs(t, err, &loadErr)
+	require.Equal(t, "../data/loader/pet-store.xyz", loadErr.File)
 }
 
 func Test_Load_MultipleFiles(t *testing.T) {
@@ -53,6 +64,8 @@ func Test_LoadedFileShouldHaveIdenticalContent(t *testing.T) {
 	specFromSingleFile, err := loader.LoadFromDir("../data/loader", "pet-store.yml")
 	require.NoError(t, err)
 
+	// the files are merged in lexical order, so only the content is identical, not the order of the keys
+	specFromMultipleFiles.KeyOrder, specFromSingleFile.KeyOrder = nil, nil
 	require.True(t, reflect.DeepEqual(specFromMultipleFiles, specFromSingleFile))
 }
 
@@ -216,3 +229,709 @@ func requireSchema(t *testing.T, spec *model.Spec) {
 	require.Equal(t, model.GenericObject{"type": "integer", "format": "int32"}, errorSchema["properties"].(model.GenericObject)["code"])
 	require.Equal(t, model.GenericObject{"type": "string"}, errorSchema["properties"].(model.GenericObject)["message"])
 }
+
+func Test_LoadFromDirWithFiles(t *testing.T) {
+	spec, files, err := loader.LoadFromDirWithFiles("../data/loader-multiple-files", "api.yml")
+	require.NoError(t, err)
+	require.NotNil(t, spec)
+
+	paths := make([]string, 0, len(files))
+	for path, modTime := range files {
+		paths = append(paths, path)
+		require.False(t, modTime.IsZero())
+		require.False(t, modTime.After(files.LastModified()))
+	}
+	sort.Strings(paths)
+
+	require.Equal(t, []string{
+		"../data/loader-multiple-files/api.yml",
+		"../data/loader-multiple-files/paths/pet-by-id.yml",
+		"../data/loader-multiple-files/paths/pets.yaml",
+		"../data/loader-multiple-files/responses/Error.yaml",
+		"../data/loader-multiple-files/schemas/Error.yaml",
+		"../data/loader-multiple-files/schemas/Pet.yml",
+		"../data/loader-multiple-files/schemas/Pets.yaml",
+	}, paths)
+}
+
+func Test_Load_FileRefs(t *testing.T) {
+	spec, files, err := loader.LoadFromDirWithFiles("../data/refs/api", "api.yaml")
+	require.NoError(t, err)
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	require.Equal(t, []string{
+		"../data/refs/api/api.yaml",
+		"../data/refs/api/models/error.json",
+		"../data/refs/api/models/link.yaml",
+		"../data/refs/api/models/node.yaml",
+		"../data/refs/api/models/owner.yaml",
+		"../data/refs/api/models/pet.yaml",
+		"../data/refs/api/models/tree.yaml",
+		"../data/refs/api/operations/pet-by-id.yaml",
+		"../data/refs/api/operations/pets.yaml",
+		"../data/refs/common.yaml",
+	}, paths)
+
+	pet := model.GenericObject{
+		"type":     "object",
+		"required": []any{"id", "name"},
+		"properties": model.GenericObject{
+			"id":   model.GenericObject{"type": "integer", "format": "int64"},
+			"name": model.GenericObject{"type": "string"},
+			"owner": model.GenericObject{
+				"type":       "object",
+				"properties": model.GenericObject{"name": model.GenericObject{"type": "string"}},
+			},
+		},
+	}
+	require.Equal(t, pet, spec.Components.Schemas["Pet"])
+
+	getPets := spec.Paths["/pets"].(model.GenericObject)["get"].(model.GenericObject)
+	require.Equal(t, "limit", getPathParam(getPets["parameters"].([]any), "limit")["name"])
+
+	responses := getPets["responses"].(model.GenericObject)
+	require.Equal(t, model.GenericObject{"type": "array", "items": pet},
+		responses["200"].(model.GenericObject)["content"].(model.GenericObject)["application/json"].(model.GenericObject)["schema"])
+	require.Equal(t, model.GenericObject{"$ref": "#/components/responses/Error"}, responses["default"])
+
+	errorSchema := spec.Components.Responses["Error"].(model.GenericObject)["content"].(model.GenericObject)["application/json"].(model.GenericObject)["schema"]
+	require.Equal(t, "object", errorSchema.(model.GenericObject)["type"])
+}
+
+func Test_Load_FileRefs_Webhooks(t *testing.T) {
+	spec, err := loader.LoadFromFS(fstest.MapFS{
+		"api/api.yaml":              {Data: []byte("openapi: 3.1.0\ninfo:\n  title: Pets\n  version: 1.0.0\nwebhooks:\n  newPet:\n    $ref: webhooks/new-pet.yaml\n")},
+		"api/webhooks/new-pet.yaml": {Data: []byte("post:\n  requestBody:\n    content:\n      application/json:\n        schema:\n          $ref: ../models/pet.yaml\n")},
+		"api/models/pet.yaml":       {Data: []byte("type: object\n")},
+	}, "api", "api.yaml")
+	require.NoError(t, err)
+
+	post := spec.Webhooks["newPet"].(model.GenericObject)["post"].(model.GenericObject)
+	require.Equal(t, model.GenericObject{"type": "object"},
+		post["requestBody"].(model.GenericObject)["content"].(model.GenericObject)["application/json"].(model.GenericObject)["schema"])
+}
+
+func Test_Load_FileRefs_Cycles(t *testing.T) {
+	spec, err := loader.LoadFromDir("../data/refs/api", "api.yaml")
+	require.NoError(t, err)
+
+	require.Equal(t, model.GenericObject{
+		"type": "object",
+		"properties": model.GenericObject{
+			"name": model.GenericObject{"type": "string"},
+			"children": model.GenericObject{
+				"type":  "array",
+				"items": model.GenericObject{"$ref": "#/components/schemas/Tree"},
+			},
+		},
+	}, spec.Components.Schemas["Tree"])
+
+	require.Equal(t, model.GenericObject{
+		"type": "object",
+		"properties": model.GenericObject{
+			"next": model.GenericObject{
+				"type": "object",
+				"properties": model.GenericObject{
+					"node": model.GenericObject{"$ref": "#/components/schemas/Node"},
+				},
+			},
+		},
+	}, spec.Components.Schemas["Node"])
+}
+
+func Test_Load_FileRefs_Unresolvable(t *testing.T) {
+	spec, err := loader.LoadFromDir("../data/refs/broken", "api.yaml")
+
+	require.ErrorContains(t, err, `../data/refs/broken/pets.yaml at '/paths/~1pets/get/responses/200': cannot resolve $ref './missing.yaml#/Ok'`)
+	require.Nil(t, spec)
+}
+
+func Test_Load_BundleRefs(t *testing.T) {
+	spec, err := loader.LoadFromDir("../data/refs/api", "api.yaml", loader.WithRefMode(loader.BundleRefs))
+	require.NoError(t, err)
+
+	getPets := spec.Paths["/pets"].(model.GenericObject)["get"].(model.GenericObject)
+	require.Equal(t, []any{model.GenericObject{"$ref": "#/components/parameters/Limit"}}, getPets["parameters"])
+	require.Equal(t, model.GenericObject{"$ref": "#/components/schemas/Pets"},
+		getPets["responses"].(model.GenericObject)["200"].(model.GenericObject)["content"].(model.GenericObject)["application/json"].(model.GenericObject)["schema"])
+
+	require.Equal(t, "limit", spec.Components.Parameters["Limit"].(model.GenericObject)["name"])
+	require.Equal(t, model.GenericObject{
+		"type":  "array",
+		"items": model.GenericObject{"$ref": "#/components/schemas/Pet"},
+	}, spec.Components.Schemas["Pets"])
+	require.Equal(t, model.GenericObject{"$ref": "#/components/schemas/owner"},
+		spec.Components.Schemas["Pet"].(model.GenericObject)["properties"].(model.GenericObject)["owner"])
+	require.Equal(t, model.GenericObject{"$ref": "#/components/schemas/Node"},
+		spec.Components.Schemas["link"].(model.GenericObject)["properties"].(model.GenericObject)["node"])
+
+	names := make([]string, 0, len(spec.Components.Schemas))
+	for name := range spec.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	require.Equal(t, []string{"Node", "Pet", "Pets", "Tree", "error", "link", "owner"}, names)
+}
+
+func Test_Load_BundleRefs_Collisions(t *testing.T) {
+	spec, err := loader.LoadFromDir("../data/refs/collisions", "api.yaml", loader.WithRefMode(loader.BundleRefs))
+	require.NoError(t, err)
+
+	require.Equal(t, model.GenericObject{"type": "string"}, spec.Components.Schemas["Pet"])
+	require.Equal(t, "string", spec.Components.Schemas["Pet_2"].(model.GenericObject)["properties"].(model.GenericObject)["name"].(model.GenericObject)["type"])
+	require.Equal(t, "string", spec.Components.Schemas["Pet_3"].(model.GenericObject)["properties"].(model.GenericObject)["vet"].(model.GenericObject)["type"])
+
+	schemaOf := func(path string) any {
+		get := spec.Paths[path].(model.GenericObject)["get"].(model.GenericObject)
+		return get["responses"].(model.GenericObject)["200"].(model.GenericObject)["content"].(model.GenericObject)["application/json"].(model.GenericObject)["schema"]
+	}
+	require.Equal(t, model.GenericObject{"$ref": "#/components/schemas/Pet_2"}, schemaOf("/pets"))
+	require.Equal(t, model.GenericObject{"$ref": "#/components/schemas/Pet_3"}, schemaOf("/vets"))
+
+	again, err := loader.LoadFromDir("../data/refs/collisions", "api.yaml", loader.WithRefMode(loader.BundleRefs))
+	require.NoError(t, err)
+	require.Equal(t, spec, again)
+}
+
+func Test_LoadFromFS(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fsys     fs.FS
+		root     string
+		baseFile string
+	}{
+		{
+			name:     "embed.FS",
+			fsys:     data.MultipleFilesSpec,
+			root:     "loader-multiple-files",
+			baseFile: "api.yml",
+		},
+		{
+			name:     "os.DirFS",
+			fsys:     os.DirFS("../data"),
+			root:     "loader-multiple-files",
+			baseFile: "api.yml",
+		},
+		{
+			name: "fstest.MapFS",
+			fsys: fstest.MapFS{
+				"api/api.yaml": {Data: []byte("openapi: 3.0.0\ninfo:\n  title: Swagger Petstore\n  version: 1.0.0\n" +
+					"components:\n  schemas:\n    Pet:\n      $ref: ./schemas/pet.yaml\n")},
+				"api/paths/pets.yaml":  {Data: []byte("paths:\n  /pets:\n    get:\n      summary: List all pets\n")},
+				"api/schemas/pet.yaml": {Data: []byte("type: object\n")},
+			},
+			root:     "api",
+			baseFile: "api.yaml",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec, files, err := loader.LoadFromFSWithFiles(tc.fsys, tc.root, tc.baseFile)
+			require.NoError(t, err)
+			require.Equal(t, "Swagger Petstore", spec.Info.Title)
+			require.Contains(t, spec.Paths, "/pets")
+			require.Contains(t, spec.Components.Schemas, "Pet")
+			require.Contains(t, files, tc.root+"/"+tc.baseFile)
+		})
+	}
+}
+
+func Test_LoadFromFS_IdenticalToLoadFromDir(t *testing.T) {
+	specFromFS, err := loader.LoadFromFS(data.MultipleFilesSpec, "loader-multiple-files", "api.yml")
+	require.NoError(t, err)
+
+	specFromDir, err := loader.LoadFromDir("../data/loader-multiple-files", "api.yml")
+	require.NoError(t, err)
+
+	require.Equal(t, specFromDir, specFromFS)
+}
+
+func Test_LoadFromFS_FileRefs(t *testing.T) {
+	spec, err := loader.LoadFromFS(os.DirFS("../data/refs"), "api", "api.yaml")
+	require.NoError(t, err)
+
+	getPets := spec.Paths["/pets"].(model.GenericObject)["get"].(model.GenericObject)
+	require.Equal(t, "limit", getPathParam(getPets["parameters"].([]any), "limit")["name"])
+}
+
+func Test_LoadFromFS_InvalidFileType(t *testing.T) {
+	spec, err := loader.LoadFromFS(os.DirFS("../data"), "loader", "pet-store.xyz")
+
+	require.ErrorContains(t, err, `loader/pet-store.xyz: not a YAML or JSON file, supported extensions are [yml|yaml|json]`)
+	require.Nil(t, spec)
+}
+
+func Test_Load_ComponentDirectories(t *testing.T) {
+	spec, err := loader.LoadFromDir("../data/loader-components", "api.yaml", loader.WithDirectory("models", "schemas"))
+	require.NoError(t, err)
+
+	require.Contains(t, spec.Paths, "/pets")
+	require.Contains(t, spec.Paths, "/pets/{petId}")
+	require.Equal(t, model.GenericObject{
+		"type":       "object",
+		"properties": model.GenericObject{"name": model.GenericObject{"type": "string"}},
+	}, spec.Components.Schemas["Pet"])
+	require.Equal(t, "limit", spec.Components.Parameters["Limit"].(model.GenericObject)["name"])
+	require.Equal(t, "A dog", spec.Components.Examples["Dog"].(model.GenericObject)["summary"])
+	require.Equal(t, true, spec.Components.RequestBodies["NewPet"].(model.GenericObject)["required"])
+	require.Contains(t, spec.Components.Headers, "X-Rate-Limit")
+	require.Len(t, spec.Components.SecuritySchemes, 2)
+	require.Equal(t, "showPetById", spec.Components.Link["GetPet"].(model.GenericObject)["operationId"])
+	require.Contains(t, spec.Components.Callbacks["onAdopted"], "{$request.body#/callbackUrl}")
+	require.Contains(t, spec.Components.PathItems["Pet"], "get")
+}
+
+func Test_Load_ComponentDirectories_WithoutCustomMapping(t *testing.T) {
+	spec, err := loader.LoadFromDir("../data/loader-components", "api.yaml")
+	require.NoError(t, err)
+
+	require.Empty(t, spec.Components.Schemas)
+}
+
+func Test_Load_ComponentDirectories_UnknownSection(t *testing.T) {
+	spec, err := loader.LoadFromDir("../data/loader-components", "api.yaml", loader.WithDirectory("models", "model"))
+
+	require.EqualError(t, err, "directory 'models' is mapped to unknown section 'model'")
+	require.Nil(t, spec)
+}
+
+func Test_Load_MergePolicy(t *testing.T) {
+	fsys := fstest.MapFS{
+		"api/api.yaml": {Data: []byte("openapi: 3.0.0\ninfo:\n  title: Swagger Petstore\n  version: 1.0.0\n" +
+			"paths:\n  /pets:\n    get:\n      summary: List all pets\n" +
+			"components:\n  schemas:\n    Pet:\n      type: object\n")},
+		"api/paths/pets.yaml":   {Data: []byte("paths:\n  /pets:\n    post:\n      summary: Create a pet\n")},
+		"api/schemas/Pet.yaml":  {Data: []byte("type: string\n")},
+		"api/schemas/Pets.yaml": {Data: []byte("type: array\n")},
+	}
+
+	testCases := []struct {
+		name        string
+		policy      loader.MergePolicy
+		wantMethods []string
+		wantPetType string
+		wantError   string
+	}{
+		{
+			name:   "should return error by default",
+			policy: loader.MergeError,
+			wantError: "api/paths/pets.yaml at '/paths/~1pets': duplicate '/pets' in paths, already defined in 'api/api.yaml'\n" +
+				"api/schemas/Pet.yaml at '/components/schemas/Pet': duplicate 'Pet' in schemas, already defined in 'api/api.yaml'",
+		},
+		{
+			name:        "should keep the entry read first",
+			policy:      loader.MergeFirstWins,
+			wantMethods: []string{"get"},
+			wantPetType: "object",
+		},
+		{
+			name:        "should keep the entry read last",
+			policy:      loader.MergeLastWins,
+			wantMethods: []string{"post"},
+			wantPetType: "string",
+		},
+		{
+			name:      "should return error for duplicate components when merging path items",
+			policy:    loader.MergePathItems,
+			wantError: "api/schemas/Pet.yaml at '/components/schemas/Pet': duplicate 'Pet' in schemas, already defined in 'api/api.yaml'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec, err := loader.LoadFromFS(fsys, "api", "api.yaml", loader.WithMergePolicy(tc.policy))
+			if tc.wantError != "" {
+				require.EqualError(t, err, tc.wantError)
+				require.Nil(t, spec)
+				return
+			}
+
+			require.NoError(t, err)
+			require.ElementsMatch(t, tc.wantMethods, keys(spec.Paths["/pets"].(model.GenericObject)))
+			require.Equal(t, tc.wantPetType, spec.Components.Schemas["Pet"].(model.GenericObject)["type"])
+			require.Contains(t, spec.Components.Schemas, "Pets")
+		})
+	}
+}
+
+func Test_Load_MergePathItems(t *testing.T) {
+	spec, err := loader.LoadFromFS(fstest.MapFS{
+		"api/api.yaml": {Data: []byte("openapi: 3.0.0\ninfo:\n  title: Swagger Petstore\n  version: 1.0.0\n" +
+			"paths:\n  /pets:\n    summary: Pets\n    get:\n      summary: List all pets\n")},
+		"api/paths/pets.yaml": {Data: []byte("paths:\n  /pets:\n    summary: Pets\n    post:\n" +
+			"      requestBody:\n        $ref: ../bodies/new-pet.yaml\n")},
+		"api/bodies/new-pet.yaml": {Data: []byte("required: true\n")},
+	}, "api", "api.yaml", loader.WithMergePolicy(loader.MergePathItems))
+	require.NoError(t, err)
+
+	pets := spec.Paths["/pets"].(model.GenericObject)
+	require.ElementsMatch(t, []string{"summary", "get", "post"}, keys(pets))
+	require.Equal(t, model.GenericObject{"required": true}, pets["post"].(model.GenericObject)["requestBody"])
+}
+
+func Test_Load_MergePathItems_Conflicts(t *testing.T) {
+	testCases := []struct {
+		name      string
+		pathB     string
+		wantError string
+	}{
+		{
+			name:      "should return error when an operation is defined twice",
+			pathB:     "paths:\n  /pets:\n    get:\n      summary: Get pets\n",
+			wantError: "api/paths/b.yaml at '/paths/~1pets/get': duplicate 'get /pets' in paths, already defined in 'api/paths/a.yaml'",
+		},
+		{
+			name:      "should return error when a path item field differs",
+			pathB:     "paths:\n  /pets:\n    summary: Animals\n    post:\n      summary: Create a pet\n",
+			wantError: "api/paths/b.yaml at '/paths/~1pets/summary': conflicting 'summary' of path '/pets', already defined in 'api/paths/a.yaml'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec, err := loader.LoadFromFS(fstest.MapFS{
+				"api/api.yaml":     {Data: []byte("openapi: 3.0.0\ninfo:\n  title: Swagger Petstore\n  version: 1.0.0\n")},
+				"api/paths/a.yaml": {Data: []byte("paths:\n  /pets:\n    summary: Pets\n    get:\n      summary: List all pets\n")},
+				"api/paths/b.yaml": {Data: []byte(tc.pathB)},
+			}, "api", "api.yaml", loader.WithMergePolicy(loader.MergePathItems))
+
+			require.EqualError(t, err, tc.wantError)
+			require.Nil(t, spec)
+		})
+	}
+}
+
+func keys(obj model.GenericObject) []string {
+	result := make([]string, 0, len(obj))
+	for key := range obj {
+		result = append(result, key)
+	}
+	return result
+}
+
+func Test_Load_Errors(t *testing.T) {
+	const info = "openapi: 3.0.0\ninfo:\n  title: Swagger Petstore\n  version: 1.0.0\n"
+	testCases := []struct {
+		name      string
+		file      string
+		content   string
+		files     map[string]string
+		wantError []loader.Error
+	}{
+		{
+			name:    "YAML syntax error",
+			file:    "api.yaml",
+			content: "openapi: 3.0.0\ninfo:\n  title: Swagger Petstore\n  version: 1.0.0: invalid\n",
+			wantError: []loader.Error{
+				{File: "api/api.yaml", Line: 4, Err: errors.New("mapping values are not allowed in this context")},
+			},
+		},
+		{
+			name:    "YAML type errors",
+			file:    "api.yaml",
+			content: "openapi: 3.0.0\ninfo:\n  title: [Swagger, Petstore]\n  version: 1.0.0\ntags:\n  - name: {pets: true}\n",
+			wantError: []loader.Error{
+				{File: "api/api.yaml", Line: 3, Column: 10, Pointer: "/info/title", Err: errors.New("cannot unmarshal !!seq into string")},
+				{File: "api/api.yaml", Line: 6, Column: 11, Pointer: "/tags/0/name", Err: errors.New("cannot unmarshal !!map into string")},
+			},
+		},
+		{
+			name:    "JSON syntax error",
+			file:    "api.json",
+			content: "{\n  \"openapi\": \"3.0.0\",\n  \"info\": {\"title\" \"Swagger Petstore\"}\n}\n",
+			wantError: []loader.Error{
+				{File: "api/api.json", Line: 3, Column: 20, Err: errors.New("invalid character '\"' after object key")},
+			},
+		},
+		{
+			name:    "JSON type error",
+			file:    "api.json",
+			content: "{\n  \"openapi\": \"3.0.0\",\n  \"info\": {\"title\": 42}\n}\n",
+			wantError: []loader.Error{
+				{File: "api/api.json", Line: 3, Column: 22, Pointer: "/info/title"},
+			},
+		},
+		{
+			name:    "duplicate component",
+			file:    "api.yaml",
+			content: info + "components:\n  schemas:\n    Pet:\n      type: object\n",
+			files:   map[string]string{"schemas/Pet.yaml": "type: string\n"},
+			wantError: []loader.Error{
+				{File: "api/schemas/Pet.yaml", Pointer: "/components/schemas/Pet", Err: errors.New("duplicate 'Pet' in schemas, already defined in 'api/api.yaml'")},
+			},
+		},
+		{
+			name:    "missing JSON pointer of a $ref",
+			file:    "api.yaml",
+			content: info + "paths:\n  /pets:\n    $ref: pets.yaml#/missing\n",
+			files:   map[string]string{"pets.yaml": "get: {}\n"},
+			wantError: []loader.Error{
+				{File: "api/api.yaml", Pointer: "/paths/~1pets", Err: errors.New("cannot resolve $ref 'pets.yaml#/missing': JSON pointer '/missing' not found")},
+			},
+		},
+		{
+			name:    "missing file of a $ref",
+			file:    "api.yaml",
+			content: info + "paths:\n  /pets:\n    $ref: pets.yaml\n",
+			wantError: []loader.Error{
+				{File: "api/api.yaml", Pointer: "/paths/~1pets"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := fstest.MapFS{"api/" + tc.file: {Data: []byte(tc.content)}}
+			for name, content := range tc.files {
+				fsys["api/"+name] = &fstest.MapFile{Data: []byte(content)}
+			}
+
+			spec, err := loader.LoadFromFS(fsys, "api", tc.file)
+			require.Error(t, err)
+			require.Nil(t, spec)
+
+			got := loadErrors(err)
+			require.Len(t, got, len(tc.wantError))
+			for i, want := range tc.wantError {
+				require.Equal(t, want.File, got[i].File)
+				require.Equal(t, want.Line, got[i].Line)
+				require.Equal(t, want.Column, got[i].Column)
+				require.Equal(t, want.Pointer, got[i].Pointer)
+				if want.Err != nil {
+					require.EqualError(t, got[i].Err, want.Err.Error())
+				}
+			}
+		})
+	}
+}
+
+func Test_Load_Errors_Aggregated(t *testing.T) {
+	spec, err := loader.LoadFromFS(fstest.MapFS{
+		"api/api.yaml":          {Data: []byte("openapi: 3.0.0\ninfo:\n  title: Swagger Petstore\n  version: 1.0.0\n")},
+		"api/paths/pets.yaml":   {Data: []byte("paths:\n  /pets: [\n")},
+		"api/schemas/Pet.yaml":  {Data: []byte("type: object\n")},
+		"api/schemas/Pets.json": {Data: []byte("{}")},
+	}, "api", "api.yaml")
+	require.Nil(t, spec)
+
+	got := loadErrors(err)
+	require.Len(t, got, 2)
+	require.Equal(t, "api/paths/pets.yaml", got[0].File)
+	require.Equal(t, 2, got[0].Line)
+	require.EqualError(t, got[1], "api/schemas/Pets.json: not a YAML file, supported extensions are [yml|yaml]")
+}
+
+func Test_Load_Errors_SectionNotAnObject(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "null section", content: "schemas:\n"},
+		{name: "list section", content: "schemas: []\n"},
+		{name: "scalar section", content: "schemas: Pet\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec, err := loader.LoadFromFS(fstest.MapFS{
+				"api/api.yaml":         {Data: []byte("openapi: 3.0.0\ninfo:\n  title: Swagger Petstore\n  version: 1.0.0\n")},
+				"api/schemas/Pet.yaml": {Data: []byte(tc.content)},
+			}, "api", "api.yaml")
+			require.Nil(t, spec)
+
+			got := loadErrors(err)
+			require.Len(t, got, 1)
+			require.EqualError(t, got[0], "api/schemas/Pet.yaml at '/schemas': 'schemas' must be an object")
+		})
+	}
+}
+
+func Test_LoadFromBytes_Errors(t *testing.T) {
+	spec, err := loader.LoadFromBytes([]byte("openapi: 3.0.0\ninfo: [\n"))
+	require.Nil(t, spec)
+	require.ErrorContains(t, err, "failed to parse as YAML or JSON: ")
+
+	var loadErr *loader.Error
+	require.ErrorAs(t, err, &loadErr)
+	require.Empty(t, loadErr.File)
+	require.Equal(t, 2, loadErr.Line)
+	require.Len(t, loadErrors(err), 2)
+}
+
+// loadErrors returns every loader.Error aggregated in err
+func loadErrors(err error) []*loader.Error {
+	if loadErr, ok := err.(*loader.Error); ok {
+		return []*loader.Error{loadErr}
+	}
+
+	var result []*loader.Error
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, child := range e.Unwrap() {
+			result = append(result, loadErrors(child)...)
+		}
+	case interface{ Unwrap() error }:
+		result = loadErrors(e.Unwrap())
+	}
+	return result
+}
+
+func Test_Load_KeyOrder(t *testing.T) {
+	fsys := fstest.MapFS{
+		"api/api.yaml": {Data: []byte(`openapi: 3.1.0
+info:
+  title: Zoo
+  version: 1.0.0
+paths:
+  /zebras:
+    get:
+      summary: List zebras
+      operationId: listZebras
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Zebra"
+  /apes:
+    $ref: ./operations/apes.yaml
+components:
+  schemas:
+    Zebra:
+      type: object
+      properties:
+        name: {type: string}
+        age: {type: integer}
+        id: {type: string}
+`)},
+		"api/operations/apes.yaml": {Data: []byte("get:\n  summary: List apes\n  operationId: listApes\n  description: All the apes\n")},
+		"api/paths/birds.yaml":     {Data: []byte("paths:\n  /birds:\n    post:\n      summary: Add a bird\n      operationId: addBird\n")},
+	}
+
+	testCases := []struct {
+		name string
+		mode loader.RefMode
+		want []string
+	}{
+		{
+			name: "inline refs",
+			mode: loader.InlineRefs,
+			want: []string{
+				`"paths":{"/zebras":{"get":{"summary":"List zebras","operationId":"listZebras","responses"`,
+				`"/apes":{"get":{"summary":"List apes","operationId":"listApes","description":"All the apes"}}`,
+				`"/birds":{"post":{"summary":"Add a bird","operationId":"addBird"}}}`,
+				`"schema":{"$ref":"#/components/schemas/Zebra"}`,
+				`"properties":{"name":{"type":"string"},"age":{"type":"integer"},"id":{"type":"string"}}`,
+			},
+		},
+		{
+			name: "dereference refs",
+			mode: loader.DereferenceRefs,
+			want: []string{
+				`"schema":{"type":"object","properties":{"name":{"type":"string"},"age":{"type":"integer"},"id":{"type":"string"}}}`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec, err := loader.LoadFromFS(fsys, "api", "api.yaml", loader.WithRefMode(tc.mode))
+			require.NoError(t, err)
+
+			content, err := json.Marshal(spec)
+			require.NoError(t, err)
+			for _, want := range tc.want {
+				require.Contains(t, string(content), want)
+			}
+		})
+	}
+}
+
+func Test_LoadFromBytes_KeyOrder(t *testing.T) {
+	spec, err := loader.LoadFromBytes([]byte(`{"openapi":"3.0.0","info":{"title":"Zoo","version":"1.0.0"},` +
+		`"paths":{"/zebras":{"get":{"summary":"List zebras"}},"/apes":{"get":{"summary":"List apes"}}}}`))
+	require.NoError(t, err)
+
+	spec.Paths["/birds"] = model.GenericObject{"get": model.GenericObject{"summary": "List birds"}}
+	content, err := json.Marshal(spec)
+	require.NoError(t, err)
+	require.Contains(t, string(content), `"paths":{"/zebras":{"get":{"summary":"List zebras"}},"/apes":{"get":{"summary":"List apes"}},"/birds":`)
+}
+
+func Test_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		dir  string
+		file string
+	}{
+		{dir: "../data/loader", file: "pet-store.yml"},
+		{dir: "../data/loader", file: "pet-store.json"},
+		{dir: "../data/xTagGroups", file: "withXTagGroups.yaml"},
+		{dir: "../data/roundtrip", file: "api.yaml"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.file, func(t *testing.T) {
+			content, err := os.ReadFile(tc.dir + "/" + tc.file)
+			require.NoError(t, err)
+			want := toJSON(t, content)
+
+			fromBytes, err := loader.LoadFromBytes(content)
+			require.NoError(t, err)
+			gotFromBytes, err := json.Marshal(fromBytes)
+			require.NoError(t, err)
+			require.JSONEq(t, want, string(gotFromBytes))
+
+			fromDir, err := loader.LoadFromDir(tc.dir, tc.file)
+			require.NoError(t, err)
+			gotFromDir, err := json.Marshal(fromDir)
+			require.NoError(t, err)
+			require.JSONEq(t, want, string(gotFromDir))
+
+			// loading the marshalled spec again gives the same document, in the same order
+			reloaded, err := loader.LoadFromBytes(gotFromBytes)
+			require.NoError(t, err)
+			gotReloaded, err := json.Marshal(reloaded)
+			require.NoError(t, err)
+			require.Equal(t, string(gotFromBytes), string(gotReloaded))
+		})
+	}
+}
+
+// toJSON returns the YAML or JSON content as JSON
+func toJSON(t *testing.T, content []byte) string {
+	var value any
+	require.NoError(t, yaml.Unmarshal(content, &value))
+	data, err := json.Marshal(stringKeys(value))
+	require.NoError(t, err)
+	return string(data)
+}
+
+// stringKeys returns the value with the keys of every map as strings
+func stringKeys(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = stringKeys(item)
+		}
+		return v
+	case map[any]any:
+		res := make(map[string]any, len(v))
+		for key, item := range v {
+			res[fmt.Sprint(key)] = stringKeys(item)
+		}
+		return res
+	case []any:
+		for i, item := range v {
+			v[i] = stringKeys(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
@@ -0,0 +1,112 @@
+package loader
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+// MergePolicy is how an entry defined in several files of a multi-file spec is merged
+type MergePolicy int
+
+const (
+	// MergeError fails loading the spec, reporting both files, this is the default
+	MergeError MergePolicy = iota
+	// MergeFirstWins keeps the entry read first. The root document is read first, then the default
+	// directories in a fixed order (paths, schemas, parameters, responses, ...), then the ones mapped
+	// with WithDirectory in the order they were added, the files of a directory in lexical order.
+	MergeFirstWins
+	// MergeLastWins keeps the entry read last
+	MergeLastWins
+	// MergePathItems merges path items defined in several files by HTTP method, an operation
+	// defined twice and any other duplicate entry is an error
+	MergePathItems
+)
+
+// merger merges the entries read from the files of a multi-file spec into its sections
+type merger struct {
+	policy   MergePolicy
+	rootFile string
+	// sources holds the file every merged entry was read from by JSON pointer, entries of the root document are not recorded
+	sources map[string]string
+	// order is the key order of the spec, merged entries follow the ones already in a section
+	order model.KeyOrder
+}
+
+// newMerger returns a merger of the entries into the spec read from rootFile with the key order
+func newMerger(policy MergePolicy, rootFile string, order model.KeyOrder) *merger {
+	return &merger{policy: policy, rootFile: rootFile, sources: map[string]string{}, order: order}
+}
+
+// merge merges the entries read from file into the section at pointer, order is the key order of the entries
+// relative to the section
+func (m *merger) merge(section model.GenericObject, entries model.GenericObject, pointer string, file string, order model.KeyOrder) error {
+	for _, key := range order.Keys("", entries) {
+		entryPointer := pointer + "/" + model.EscapeToken(key)
+		existing, ok := section[key]
+		if !ok {
+			section[key] = entries[key]
+			m.sources[entryPointer] = file
+			m.order.Append(pointer, key)
+			m.order.Copy(entryPointer, order, "/"+model.EscapeToken(key))
+			continue
+		}
+
+		switch {
+		case m.policy == MergeFirstWins:
+		case m.policy == MergeLastWins:
+			section[key] = entries[key]
+			m.sources[entryPointer] = file
+			m.order.Copy(entryPointer, order, "/"+model.EscapeToken(key))
+		case m.policy == MergePathItems && pointer == sectionPointer(keyPaths):
+			if err := m.mergePathItem(existing, entries[key], key, entryPointer, file, order); err != nil {
+				return err
+			}
+		default:
+			return &Error{File: file, Pointer: entryPointer, Err: fmt.Errorf("duplicate '%s' in %s, already defined in '%s'",
+				key, pointer[strings.LastIndex(pointer, "/")+1:], m.source(entryPointer))}
+		}
+	}
+	return nil
+}
+
+// mergePathItem merges the operations of the path item read from file into the existing one,
+// order is the key order of the entries holding the path item
+func (m *merger) mergePathItem(existing, item any, path string, pointer string, file string, order model.KeyOrder) error {
+	existingItem, ok := normalizeMap(existing).(model.GenericObject)
+	pathItem, isObject := normalizeMap(item).(model.GenericObject)
+	if !ok || !isObject {
+		return &Error{File: file, Pointer: pointer, Err: fmt.Errorf("duplicate '%s' in paths, already defined in '%s'", path, m.source(pointer))}
+	}
+
+	itemPointer := "/" + model.EscapeToken(path)
+	for _, field := range order.Keys(itemPointer, pathItem) {
+		fieldPointer := pointer + "/" + model.EscapeToken(field)
+		current, defined := existingItem[field]
+		switch {
+		case !defined:
+			existingItem[field] = pathItem[field]
+			m.sources[fieldPointer] = file
+			m.order.Append(pointer, field)
+			m.order.Copy(fieldPointer, order, itemPointer+"/"+model.EscapeToken(field))
+		case slices.Contains(model.HTTPMethods, field):
+			return &Error{File: file, Pointer: fieldPointer, Err: fmt.Errorf("duplicate '%s %s' in paths, already defined in '%s'", field, path, m.source(fieldPointer))}
+		case !reflect.DeepEqual(current, pathItem[field]):
+			return &Error{File: file, Pointer: fieldPointer, Err: fmt.Errorf("conflicting '%s' of path '%s', already defined in '%s'", field, path, m.source(fieldPointer))}
+		}
+	}
+	return nil
+}
+
+// source returns the file the entry at pointer, or its closest parent, was read from
+func (m *merger) source(pointer string) string {
+	for ; pointer != ""; pointer = pointer[:strings.LastIndex(pointer, "/")] {
+		if file, ok := m.sources[pointer]; ok {
+			return file
+		}
+	}
+	return m.rootFile
+}
@@ -0,0 +1,86 @@
+package loader
+
+import "slices"
+
+// RefMode is how the relative file references ($ref) of a spec are resolved
+type RefMode int
+
+const (
+	// InlineRefs replaces every file reference with its target, this is the default
+	InlineRefs RefMode = iota
+	// BundleRefs moves the target of every file reference into the components of the spec
+	// and replaces the reference with a local one, e.g. #/components/schemas/Pet
+	BundleRefs
+	// DereferenceRefs inlines the target of every file reference and then of every local reference,
+	// see Dereference
+	DereferenceRefs
+)
+
+// keyPaths is the section of the spec holding the paths
+const keyPaths = "paths"
+
+// defaultDirectories are the directories merged into the spec by default, each into the section
+// of the same name. The order is kept so entries found in several directories are merged the same way.
+var defaultDirectories = []string{
+	keyPaths, "schemas", "parameters", "responses", "examples", "requestBodies",
+	"headers", "securitySchemes", "links", "callbacks", "pathItems",
+}
+
+// loadOptions customize how a spec is loaded
+type loadOptions struct {
+	refMode     RefMode
+	mergePolicy MergePolicy
+	// customDirectories maps directories to the section of the spec they are merged into
+	customDirectories []directory
+}
+
+// directory is a directory of a multi-file spec and the section of the spec it is merged into
+type directory struct {
+	dir     string
+	section string
+}
+
+// Option customizes how a spec is loaded
+type Option func(*loadOptions)
+
+// WithRefMode sets how the relative file references of the spec are resolved
+func WithRefMode(mode RefMode) Option {
+	return func(o *loadOptions) {
+		o.refMode = mode
+	}
+}
+
+// WithMergePolicy sets how an entry defined in several files of the spec is merged, see MergePolicy
+func WithMergePolicy(policy MergePolicy) Option {
+	return func(o *loadOptions) {
+		o.mergePolicy = policy
+	}
+}
+
+// WithDirectory merges the files in the directory into a section of the spec, e.g. WithDirectory("models", "schemas").
+// The section is "paths" or a components section, every section is merged from the directory of the same name by default.
+func WithDirectory(dir, section string) Option {
+	return func(o *loadOptions) {
+		o.customDirectories = append(o.customDirectories, directory{dir: dir, section: section})
+	}
+}
+
+// directories returns the directories to merge into the spec, a custom mapping replaces the default one of a directory
+func (o *loadOptions) directories() []directory {
+	directories := make([]directory, 0, len(defaultDirectories)+len(o.customDirectories))
+	for _, dir := range defaultDirectories {
+		if !slices.ContainsFunc(o.customDirectories, func(d directory) bool { return d.dir == dir }) {
+			directories = append(directories, directory{dir: dir, section: dir})
+		}
+	}
+	return append(directories, o.customDirectories...)
+}
+
+// buildOptions build loadOptions from applying Option to defaults
+func buildOptions(opts ...Option) *loadOptions {
+	options := &loadOptions{refMode: InlineRefs, mergePolicy: MergeError}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
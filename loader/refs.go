@@ -0,0 +1,437 @@
+package loader
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+// keyRef is the key of a reference object
+const keyRef = "$ref"
+
+// refResolver resolves the relative file references of a spec, each referenced document is read once
+type refResolver struct {
+	rootFile  string
+	reader    *reader
+	mode      RefMode
+	documents map[string]model.GenericObject
+	// orders holds the key order of each document
+	orders map[string]model.KeyOrder
+	// order is the key order of the spec, the order of a target is copied to where it is placed
+	order model.KeyOrder
+	// rootRelative holds the files merged into the root document, their local references point into the root document
+	rootRelative map[string]bool
+	// inlining holds the references being inlined and the JSON pointer of where, a reference met again
+	// while it is being inlined is a cycle and is replaced by a local reference to that JSON pointer
+	inlining map[string]string
+	// bundled holds the references moved into the components and the JSON pointer of where
+	bundled map[string]string
+	// components holds the components of the spec by section, bundled targets are added to them
+	components map[string]*model.GenericObject
+	// sources holds the file every entry merged into the root document was read from by JSON pointer
+	sources map[string]string
+}
+
+// refContext is the file the references being resolved appear in
+type refContext struct {
+	file         string
+	rootRelative bool
+}
+
+// newRefResolver returns a refResolver for the spec in rootFile
+func newRefResolver(rootFile string, reader *reader, mode RefMode) *refResolver {
+	rootFile = reader.clean(rootFile)
+	return &refResolver{
+		rootFile:     rootFile,
+		reader:       reader,
+		mode:         mode,
+		documents:    map[string]model.GenericObject{},
+		orders:       map[string]model.KeyOrder{},
+		order:        model.KeyOrder{},
+		rootRelative: map[string]bool{rootFile: true},
+		inlining:     map[string]string{},
+		bundled:      map[string]string{},
+		components:   map[string]*model.GenericObject{},
+		sources:      map[string]string{},
+	}
+}
+
+// resolveObject resolves the references in every value of the object, pointer is the location of the
+// object in the root document. The entries merged from other files are resolved relative to the file they were read from.
+func (r *refResolver) resolveObject(obj model.GenericObject, ctx refContext, pointer string) error {
+	for _, key := range sortedKeys(obj) {
+		keyPointer := pointer + "/" + model.EscapeToken(key)
+		keyCtx := ctx
+		if source, ok := r.sources[keyPointer]; ok {
+			keyCtx = refContext{file: r.reader.clean(source), rootRelative: true}
+		}
+
+		value, err := r.resolve(obj[key], keyCtx, keyPointer)
+		if err != nil {
+			return err
+		}
+		obj[key] = value
+	}
+	return nil
+}
+
+// resolve returns the value with its file references inlined, pointer is the location of the value in the root document
+func (r *refResolver) resolve(value any, ctx refContext, pointer string) (any, error) {
+	switch v := normalizeMap(value).(type) {
+	case model.GenericObject:
+		if ref, ok := v[keyRef].(string); ok {
+			return r.resolveRef(v, ref, ctx, pointer)
+		}
+		return v, r.resolveObject(v, ctx, pointer)
+	case []any:
+		for i, item := range v {
+			resolved, err := r.resolve(item, ctx, pointer+"/"+strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
+
+// resolveRef returns the target of the reference object, references to other documents on the web are left as they are
+func (r *refResolver) resolveRef(obj model.GenericObject, ref string, ctx refContext, pointer string) (any, error) {
+	target, fragment, _ := strings.Cut(ref, "#")
+	if strings.Contains(target, "://") {
+		return obj, nil
+	}
+
+	file := ctx.file
+	if target != "" {
+		file = r.reader.join(r.reader.dir(ctx.file), target)
+	} else if ctx.rootRelative {
+		return obj, nil
+	}
+
+	if file == r.rootFile {
+		return withSiblings(model.GenericObject{keyRef: "#" + fragment}, obj), nil
+	}
+
+	key := file + "#" + fragment
+	if at, ok := r.bundled[key]; ok {
+		return withSiblings(model.GenericObject{keyRef: "#" + encodePointer(at)}, obj), nil
+	}
+	if at, ok := r.inlining[key]; ok {
+		return withSiblings(model.GenericObject{keyRef: "#" + encodePointer(at)}, obj), nil
+	}
+
+	doc, err := r.document(file)
+	if err != nil {
+		return nil, &Error{File: ctx.file, Pointer: pointer, Err: fmt.Errorf("cannot resolve $ref '%s': %w", ref, err)}
+	}
+
+	value, err := lookup(doc, fragment)
+	if err != nil {
+		return nil, &Error{File: ctx.file, Pointer: pointer, Err: fmt.Errorf("cannot resolve $ref '%s': %w", ref, err)}
+	}
+	from, err := url.PathUnescape(fragment)
+	if err != nil {
+		from = fragment
+	}
+
+	if r.mode == BundleRefs {
+		if isComponent(pointer) {
+			// the reference is a component itself, its target is bundled under the name of the component
+			r.bundled[key] = pointer
+			r.order.Copy(pointer, r.orders[file], from)
+			return r.resolveTarget(obj, value, file, pointer)
+		}
+		if section := componentSection(pointer); section != "" {
+			at, err := r.bundle(section, key, componentName(fragment, file), file, value, from)
+			if err != nil {
+				return nil, err
+			}
+			return withSiblings(model.GenericObject{keyRef: "#" + encodePointer(at)}, obj), nil
+		}
+	}
+
+	r.inlining[key] = pointer
+	defer delete(r.inlining, key)
+	r.order.Copy(pointer, r.orders[file], from)
+	return r.resolveTarget(obj, value, file, pointer)
+}
+
+// resolveTarget returns the target of the reference object with its references resolved
+func (r *refResolver) resolveTarget(obj model.GenericObject, value any, file string, pointer string) (any, error) {
+	resolved, err := r.resolve(normalize(value), refContext{file: file, rootRelative: r.rootRelative[file]}, pointer)
+	if err != nil {
+		return nil, err
+	}
+	if resolvedObj, ok := resolved.(model.GenericObject); ok {
+		return withSiblings(resolvedObj, obj), nil
+	}
+	return resolved, nil
+}
+
+// bundle adds the target of the reference to the components section under a free name
+// and returns the JSON pointer of the component, from is the JSON pointer of the target in the file
+func (r *refResolver) bundle(section, key, name, file string, value any, from string) (string, error) {
+	components := r.components[section]
+	if *components == nil {
+		*components = model.GenericObject{}
+	}
+	name = freeName(*components, name)
+	at := "/components/" + section + "/" + model.EscapeToken(name)
+
+	// the name is reserved before resolving the target, so references back to it are not bundled again
+	r.bundled[key] = at
+	(*components)[name] = nil
+	r.order.Append(sectionPointer(section), name)
+	r.order.Copy(at, r.orders[file], from)
+	resolved, err := r.resolveTarget(model.GenericObject{}, value, file, at)
+	if err != nil {
+		return "", err
+	}
+	(*components)[name] = resolved
+	return at, nil
+}
+
+// document returns the parsed content of the file, reading it on first use
+func (r *refResolver) document(file string) (model.GenericObject, error) {
+	if doc, ok := r.documents[file]; ok {
+		return doc, nil
+	}
+
+	if !isYamlFile(file) && !isJSONFile(file) {
+		return nil, errUnsupportedFile(file, "YAML or JSON", "yml|yaml|json")
+	}
+
+	contentBytes, err := r.reader.read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML is a superset of JSON, so both are parsed as YAML
+	doc, order, err := decodeYaml[model.GenericObject](file, contentBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	r.documents[file] = doc
+	r.orders[file] = order
+	return doc, nil
+}
+
+// lookup returns the value at the JSON pointer fragment of the document
+func lookup(doc model.GenericObject, fragment string) (any, error) {
+	decoded, err := url.PathUnescape(fragment)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON pointer '%s': %w", fragment, err)
+	}
+	if decoded == "" {
+		return doc, nil
+	}
+	if !strings.HasPrefix(decoded, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer '%s'", fragment)
+	}
+
+	var value any = doc
+	for _, token := range strings.Split(decoded[1:], "/") {
+		token = model.UnescapeToken(token)
+
+		var found bool
+		switch v := normalizeMap(value).(type) {
+		case model.GenericObject:
+			value, found = v[token]
+		case []any:
+			if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(v) {
+				value, found = v[i], true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("JSON pointer '%s' not found", fragment)
+		}
+	}
+	return value, nil
+}
+
+// normalize returns a deep copy of the value with every object as a model.GenericObject
+func normalize(value any) any {
+	switch v := normalizeMap(value).(type) {
+	case model.GenericObject:
+		res := make(model.GenericObject, len(v))
+		for key, item := range v {
+			res[key] = normalize(item)
+		}
+		return res
+	case []any:
+		res := make([]any, len(v))
+		for i, item := range v {
+			res[i] = normalize(item)
+		}
+		return res
+	default:
+		return value
+	}
+}
+
+// normalizeMap returns the map as a model.GenericObject without copying it when possible
+func normalizeMap(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return model.GenericObject(v)
+	case map[any]any:
+		res := make(model.GenericObject, len(v))
+		for key, item := range v {
+			res[fmt.Sprintf("%v", key)] = item
+		}
+		return res
+	default:
+		return value
+	}
+}
+
+// withSiblings returns the resolved object with the keys next to $ref in the reference object,
+// such as summary and description, overriding the ones of the target
+func withSiblings(resolved model.GenericObject, refObj model.GenericObject) model.GenericObject {
+	if len(refObj) == 1 {
+		return resolved
+	}
+	for key, value := range refObj {
+		if key != keyRef {
+			resolved[key] = value
+		}
+	}
+	return resolved
+}
+
+// sortedKeys returns the keys of the object in order, so the spec is resolved the same way every time
+func sortedKeys(obj model.GenericObject) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// encodePointer returns the JSON pointer encoded to be used as a URI fragment
+func encodePointer(pointer string) string {
+	tokens := strings.Split(pointer, "/")
+	for i, token := range tokens {
+		tokens[i] = url.PathEscape(token)
+	}
+	return strings.Join(tokens, "/")
+}
+
+// resolveSpec resolves the file references in the paths, webhooks and components of the spec, the entries merged
+// from other files are resolved relative to the file they were read from as recorded in sources
+func (r *refResolver) resolveSpec(spec *model.Spec, sources map[string]string) error {
+	for _, file := range sources {
+		r.rootRelative[r.reader.clean(file)] = true
+	}
+	r.sources = sources
+	if spec.KeyOrder == nil {
+		spec.KeyOrder = model.KeyOrder{}
+	}
+	r.order = spec.KeyOrder
+
+	r.components = spec.Components.Sections()
+
+	// components are resolved before paths and webhooks, so bundled targets are named after the components referencing them
+	sections := []struct {
+		pointer string
+		obj     model.GenericObject
+	}{
+		{"/components/schemas", spec.Components.Schemas},
+		{"/components/parameters", spec.Components.Parameters},
+		{"/components/responses", spec.Components.Responses},
+		{"/components/examples", spec.Components.Examples},
+		{"/components/requestBodies", spec.Components.RequestBodies},
+		{"/components/headers", spec.Components.Headers},
+		{"/components/securitySchemes", spec.Components.SecuritySchemes},
+		{"/components/links", spec.Components.Link},
+		{"/components/callbacks", spec.Components.Callbacks},
+		{"/components/pathItems", spec.Components.PathItems},
+		{"/paths", spec.Paths},
+		{"/webhooks", spec.Webhooks},
+	}
+	for _, section := range sections {
+		if err := r.resolveObject(section.obj, refContext{file: r.rootFile, rootRelative: true}, section.pointer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sectionPointer returns the JSON pointer of the section, "paths" or a components section
+func sectionPointer(section string) string {
+	if section == keyPaths {
+		return "/" + keyPaths
+	}
+	return "/components/" + section
+}
+
+// isComponent reports whether the JSON pointer is the one of a component, e.g. /components/schemas/Pet
+func isComponent(pointer string) bool {
+	tokens := strings.Split(pointer, "/")
+	return len(tokens) == 4 && tokens[1] == "components"
+}
+
+// componentSection returns the components section of the object referenced at the JSON pointer,
+// empty when the object must be inlined like path items and webhooks
+func componentSection(pointer string) string {
+	tokens := strings.Split(pointer, "/")[1:]
+	n := len(tokens)
+	if n < 2 {
+		return ""
+	}
+
+	parent, last := tokens[n-2], tokens[n-1]
+	switch {
+	case n == 2 && (parent == "paths" || parent == "webhooks"):
+		return ""
+	case parent == "properties" || (n >= 3 && tokens[n-3] == "properties"):
+		return "schemas"
+	case parent == "parameters", parent == "responses", parent == "headers", parent == "examples",
+		parent == "links", parent == "callbacks":
+		return parent
+	case n >= 3 && tokens[n-3] == "callbacks":
+		return ""
+	case last == "requestBody":
+		return "requestBodies"
+	default:
+		return "schemas"
+	}
+}
+
+// componentNameReplacer matches the characters not allowed in component names
+var componentNameReplacer = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// componentName returns the name of a component bundled from the fragment of a file,
+// the last token of the fragment or the name of the file when there is no fragment
+func componentName(fragment, file string) string {
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	if decoded, err := url.PathUnescape(fragment); err == nil && strings.Trim(decoded, "/") != "" {
+		tokens := strings.Split(decoded, "/")
+		name = model.UnescapeToken(tokens[len(tokens)-1])
+	}
+	return componentNameReplacer.ReplaceAllString(name, "_")
+}
+
+// freeName returns the name, suffixed with a number when it is already used by another component
+func freeName(components model.GenericObject, name string) string {
+	if _, ok := components[name]; !ok {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s_%d", name, i)
+		if _, ok := components[candidate]; !ok {
+			return candidate
+		}
+	}
+}
@@ -1,35 +1,28 @@
 package loader
 
 import (
+	"errors"
 	"fmt"
-	"maps"
-	"os"
 	"path/filepath"
 	"strings"
 
-	"gopkg.in/yaml.v3"
-
 	"github.com/bdpiprava/scalar-go/model"
 )
 
-// readYamlFile reads a YAML file and unmarshalls it into the provided data structure.
-func readYamlFile[T any](path string) (T, error) {
+// readYamlFile reads a YAML file and unmarshalls it into the provided data structure,
+// the order of its keys is returned along with it.
+func readYamlFile[T any](path string, r *reader) (T, model.KeyOrder, error) {
 	var data T
 	if !isYamlFile(path) {
-		return data, fmt.Errorf("file '%s' is not a YAML file, supported extensions are [yml|yaml]", path)
+		return data, nil, errUnsupportedFile(path, "YAML", "yml|yaml")
 	}
 
-	contentBytes, err := os.ReadFile(path)
+	contentBytes, err := r.read(path)
 	if err != nil {
-		return data, err
+		return data, nil, err
 	}
 
-	err = yaml.Unmarshal(contentBytes, &data)
-	if err != nil {
-		return data, err
-	}
-
-	return data, err
+	return decodeYaml[T](path, contentBytes)
 }
 
 // isYamlFile checks if the file is a YAML file.
@@ -37,45 +30,61 @@ func isYamlFile(path string) bool {
 	return strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")
 }
 
-// readDirRecursively reads a directory recursively and returns as model.GenericObject
-func readDirRecursively(dir string, key string) (*model.GenericObject, error) {
-	data := model.GenericObject{}
-	if !exists(dir) {
-		return &data, nil
+// readDirRecursively reads a directory recursively and merges the entries of every file into the target
+// section at pointer with the merger, a file holding the key is merged from that key.
+// Every file is read, the problems found are aggregated into the returned error.
+func readDirRecursively(dir string, key string, pointer string, r *reader, m *merger, target model.GenericObject) error {
+	if !r.exists(dir) {
+		return nil
 	}
-	files, err := os.ReadDir(dir)
+	entries, err := r.readDir(dir)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	for _, file := range files {
+	var errs []error
+	for _, file := range entries {
 		fileName := file.Name()
 		if file.IsDir() {
-			subData, err := readDirRecursively(filepath.Join(dir, fileName), key)
-			if err != nil {
-				return &data, err
+			if err := readDirRecursively(r.join(dir, fileName), key, pointer, r, m, target); err != nil {
+				errs = append(errs, err)
 			}
-
-			maps.Copy(data, *subData)
 			continue
 		}
 
-		ext := filepath.Ext(fileName)
-		fileContent, err := readYamlFile[model.GenericObject](filepath.Join(dir, fileName))
+		path := r.join(dir, fileName)
+		fileContent, fileOrder, err := readYamlFile[model.GenericObject](path, r)
 		if err != nil {
-			return nil, err
+			errs = append(errs, err)
+			continue
 		}
 
 		if len(fileContent) == 0 {
 			continue
 		}
 
-		if content, ok := fileContent[key]; ok {
-			maps.Copy(data, content.(model.GenericObject))
+		// order holds the order of the keys of the content as if it were the section
+		content := fileContent
+		order := model.KeyOrder{}
+		if value, ok := fileContent[key]; ok {
+			section, ok := value.(model.GenericObject)
+			if !ok {
+				errs = append(errs, &Error{File: path, Pointer: "/" + model.EscapeToken(key), Err: fmt.Errorf("'%s' must be an object", key)})
+				continue
+			}
+			content = section
+			order.Copy("", fileOrder, "/"+model.EscapeToken(key))
 		} else {
-			data[strings.TrimSuffix(fileName, ext)] = fileContent
+			name := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+			content = model.GenericObject{name: fileContent}
+			order.Copy("/"+model.EscapeToken(name), fileOrder, "")
+			order[""] = []string{name}
+		}
+
+		if err := m.merge(target, content, pointer, path, order); err != nil {
+			errs = append(errs, err)
 		}
 	}
 
-	return &data, nil
+	return errors.Join(errs...)
 }
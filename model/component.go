@@ -2,14 +2,45 @@ package model
 
 // Components holds a set of reusable objects for different aspects of the OAS.
 type Components struct {
-	Schemas         GenericObject `yaml:"schemas" json:"schemas"`
-	Parameters      GenericObject `yaml:"parameters" json:"parameters"`
-	Responses       GenericObject `yaml:"responses" json:"responses"`
-	Examples        GenericObject `yaml:"examples" json:"examples"`
-	RequestBodies   GenericObject `yaml:"requestBodies" json:"requestBodies"`
-	Headers         GenericObject `yaml:"headers" json:"headers"`
-	SecuritySchemes GenericObject `yaml:"securitySchemes" json:"securitySchemes"`
-	Link            GenericObject `yaml:"links" json:"links"`
-	Callbacks       GenericObject `yaml:"callbacks" json:"callbacks"`
-	PathItems       GenericObject `yaml:"pathItems" json:"pathItems"`
+	Schemas         GenericObject `yaml:"schemas,omitempty" json:"schemas,omitempty"`
+	Parameters      GenericObject `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	Responses       GenericObject `yaml:"responses,omitempty" json:"responses,omitempty"`
+	Examples        GenericObject `yaml:"examples,omitempty" json:"examples,omitempty"`
+	RequestBodies   GenericObject `yaml:"requestBodies,omitempty" json:"requestBodies,omitempty"`
+	Headers         GenericObject `yaml:"headers,omitempty" json:"headers,omitempty"`
+	SecuritySchemes GenericObject `yaml:"securitySchemes,omitempty" json:"securitySchemes,omitempty"`
+	Link            GenericObject `yaml:"links,omitempty" json:"links,omitempty"`
+	Callbacks       GenericObject `yaml:"callbacks,omitempty" json:"callbacks,omitempty"`
+	PathItems       GenericObject `yaml:"pathItems,omitempty" json:"pathItems,omitempty"`
+	// Extensions holds the x- extensions and any other field the components have no field for
+	Extensions GenericObject `yaml:",inline" json:"-"`
+}
+
+// MarshalJSON returns the JSON encoding of the components followed by the extensions
+func (c Components) MarshalJSON() ([]byte, error) {
+	type components Components
+	return marshalWithExtensions(components(c), c.Extensions)
+}
+
+// Sections returns the sections of the components by name, e.g. schemas
+func (c *Components) Sections() map[string]*GenericObject {
+	return map[string]*GenericObject{
+		"schemas":         &c.Schemas,
+		"parameters":      &c.Parameters,
+		"responses":       &c.Responses,
+		"examples":        &c.Examples,
+		"requestBodies":   &c.RequestBodies,
+		"headers":         &c.Headers,
+		"securitySchemes": &c.SecuritySchemes,
+		"links":           &c.Link,
+		"callbacks":       &c.Callbacks,
+		"pathItems":       &c.PathItems,
+	}
+}
+
+// isEmpty checks if none of the sections or extensions holds an entry
+func (c Components) isEmpty() bool {
+	return len(c.Schemas) == 0 && len(c.Parameters) == 0 && len(c.Responses) == 0 && len(c.Examples) == 0 &&
+		len(c.RequestBodies) == 0 && len(c.Headers) == 0 && len(c.SecuritySchemes) == 0 && len(c.Link) == 0 &&
+		len(c.Callbacks) == 0 && len(c.PathItems) == 0 && len(c.Extensions) == 0
 }
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// marshalWithExtensions returns the JSON encoding of the value followed by the extensions,
+// the extensions named after a field of the value are left out
+func marshalWithExtensions(v any, extensions GenericObject) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extensions) == 0 {
+		return data, err
+	}
+
+	known := jsonFields(reflect.TypeOf(v))
+	fields := make(GenericObject, len(extensions))
+	for key, value := range extensions {
+		if !known[key] {
+			fields[key] = value
+		}
+	}
+	if len(fields) == 0 {
+		return data, nil
+	}
+
+	extensionsData, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "{}" {
+		return extensionsData, nil
+	}
+	return append(append(data[:len(data)-1], ','), extensionsData[1:]...), nil
+}
+
+// setExtensions sets the Extensions of the value, and of the values within, to the fields of the raw
+// JSON object they have no field for. The objects within a GenericObject are made GenericObject as well.
+func setExtensions(v reflect.Value, raw any) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			setExtensions(v.Elem(), raw)
+		}
+	case reflect.Slice:
+		items, _ := raw.([]any)
+		for i := 0; i < v.Len() && i < len(items); i++ {
+			setExtensions(v.Index(i), items[i])
+		}
+	case reflect.Map:
+		fields, _ := raw.(map[string]any)
+		switch v.Type().Elem().Kind() {
+		case reflect.Interface:
+			if v.Type() != reflect.TypeOf(GenericObject{}) {
+				return
+			}
+			for _, key := range v.MapKeys() {
+				item := reflect.New(v.Type().Elem()).Elem()
+				if value := genericValue(v.MapIndex(key).Interface()); value != nil {
+					item.Set(reflect.ValueOf(value))
+				}
+				v.SetMapIndex(key, item)
+			}
+		case reflect.Pointer:
+			for _, key := range v.MapKeys() {
+				setExtensions(v.MapIndex(key), fields[key.String()])
+			}
+		case reflect.Struct:
+			for _, key := range v.MapKeys() {
+				// map values cannot be set in place, so the value is copied and put back
+				item := reflect.New(v.Type().Elem()).Elem()
+				item.Set(v.MapIndex(key))
+				setExtensions(item, fields[key.String()])
+				v.SetMapIndex(key, item)
+			}
+		}
+	case reflect.Struct:
+		fields, _ := raw.(map[string]any)
+		for i := 0; i < v.NumField(); i++ {
+			name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+			if value, ok := fields[name]; ok && name != "-" {
+				setExtensions(v.Field(i), value)
+			}
+		}
+
+		extensions := v.FieldByName("Extensions")
+		if !extensions.IsValid() || extensions.Type() != reflect.TypeOf(GenericObject{}) {
+			return
+		}
+		known := jsonFields(v.Type())
+		var res GenericObject
+		for key, value := range fields {
+			if known[key] {
+				continue
+			}
+			if res == nil {
+				res = GenericObject{}
+			}
+			res[key] = genericValue(value)
+		}
+		extensions.Set(reflect.ValueOf(res))
+	}
+}
+
+// jsonFields returns the JSON names of the fields of the struct type, fields of embedded structs included
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch {
+		case name == "-":
+		case field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct:
+			for embedded := range jsonFields(field.Type) {
+				fields[embedded] = true
+			}
+		case name != "":
+			fields[name] = true
+		case field.IsExported():
+			fields[field.Name] = true
+		}
+	}
+	return fields
+}
@@ -9,18 +9,40 @@ type Info struct {
 	Contact        *Contact `json:"contact,omitempty" yaml:"contact,omitempty"`
 	License        *License `json:"license,omitempty" yaml:"license,omitempty"`
 	Version        string   `json:"version" yaml:"version"`
+	// Extensions holds the x- extensions and any other field the info has no field for
+	Extensions GenericObject `json:"-" yaml:",inline"`
 }
 
 // Contact structure is generated from "#/$defs/contact".
 type Contact struct {
-	Name  *string `json:"name,omitempty" yaml:"name,omitempty"`
-	URL   *string `json:"url,omitempty" yaml:"url,omitempty"`
-	Email *string `json:"email,omitempty" yaml:"email,omitempty"`
+	Name       *string       `json:"name,omitempty" yaml:"name,omitempty"`
+	URL        *string       `json:"url,omitempty" yaml:"url,omitempty"`
+	Email      *string       `json:"email,omitempty" yaml:"email,omitempty"`
+	Extensions GenericObject `json:"-" yaml:",inline"`
 }
 
 // License structure is generated from "#/$defs/license".
 type License struct {
-	Name       string  `json:"name" yaml:"name"`
-	Identifier *string `json:"identifier,omitempty" yaml:"identifier,omitempty"`
-	URL        *string `json:"url,omitempty" yaml:"url,omitempty"`
+	Name       string        `json:"name" yaml:"name"`
+	Identifier *string       `json:"identifier,omitempty" yaml:"identifier,omitempty"`
+	URL        *string       `json:"url,omitempty" yaml:"url,omitempty"`
+	Extensions GenericObject `json:"-" yaml:",inline"`
+}
+
+// MarshalJSON returns the JSON encoding of the info followed by the extensions
+func (i Info) MarshalJSON() ([]byte, error) {
+	type info Info
+	return marshalWithExtensions(info(i), i.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of the contact followed by the extensions
+func (c Contact) MarshalJSON() ([]byte, error) {
+	type contact Contact
+	return marshalWithExtensions(contact(c), c.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of the license followed by the extensions
+func (l License) MarshalJSON() ([]byte, error) {
+	type license License
+	return marshalWithExtensions(license(l), l.Extensions)
 }
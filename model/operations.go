@@ -0,0 +1,111 @@
+package model
+
+import (
+	"iter"
+	"slices"
+)
+
+// PathOperation is an operation of the spec with the path and HTTP method it is documented under
+type PathOperation struct {
+	Path string
+	// Method is the HTTP method in lower case as written in the spec, e.g. get
+	Method string
+	// Operation holds the tags, operationId and the rest of the operation as written in the spec
+	Operation *Operation
+	// Parameters are the parameters of the path item merged with the ones of the operation, an operation
+	// parameter overrides the path parameter with the same name and location
+	Parameters []Parameter
+}
+
+// Operations returns an iterator over the operations of the paths in order. The methods are read from the
+// generic path items and every operation is decoded on its own, so an invalid part of a path item or of an
+// operation does not hide the others: an operation is yielded with the fields that could be decoded.
+func (s Spec) Operations() iter.Seq[PathOperation] {
+	return func(yield func(PathOperation) bool) {
+		for _, path := range s.KeyOrder.Keys("/paths", s.Paths) {
+			item, ok := genericObject(s.Paths[path])
+			if !ok {
+				continue
+			}
+
+			var pathParams []Parameter
+			_ = Decode(item["parameters"], &pathParams)
+			for _, method := range HTTPMethods {
+				value, ok := item[method]
+				if !ok {
+					continue
+				}
+				operation := &Operation{}
+				_ = Decode(value, operation)
+
+				op := PathOperation{
+					Path:       path,
+					Method:     method,
+					Operation:  operation,
+					Parameters: effectiveParameters(pathParams, operation.Parameters),
+				}
+				if !yield(op) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// FindOperation returns the operation with the operationId, none is found for an empty operationId
+func (s Spec) FindOperation(operationID string) (PathOperation, bool) {
+	if operationID == "" {
+		return PathOperation{}, false
+	}
+	for op := range s.Operations() {
+		if op.Operation.OperationID == operationID {
+			return op, true
+		}
+	}
+	return PathOperation{}, false
+}
+
+// OperationsByTag returns the operations tagged with the tag in order
+func (s Spec) OperationsByTag(tag string) []PathOperation {
+	var res []PathOperation
+	for op := range s.Operations() {
+		if slices.Contains(op.Operation.Tags, tag) {
+			res = append(res, op)
+		}
+	}
+	return res
+}
+
+// genericObject returns the value as a GenericObject when it is an object
+func genericObject(value any) (GenericObject, bool) {
+	switch v := value.(type) {
+	case GenericObject:
+		return v, true
+	case map[string]any:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+// effectiveParameters returns the path parameters with the ones overridden by the operation replaced,
+// followed by the other operation parameters
+func effectiveParameters(pathParams []Parameter, operationParams []Parameter) []Parameter {
+	res := slices.Clone(pathParams)
+	for _, param := range operationParams {
+		if i := slices.IndexFunc(pathParams, param.sameAs); i >= 0 {
+			res[i] = param
+		} else {
+			res = append(res, param)
+		}
+	}
+	return res
+}
+
+// sameAs checks if both parameters are the same reference or have the same name and location
+func (p Parameter) sameAs(other Parameter) bool {
+	if p.Ref != "" || other.Ref != "" {
+		return p.Ref == other.Ref
+	}
+	return p.Name == other.Name && p.In == other.In
+}
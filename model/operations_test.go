@@ -0,0 +1,100 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+func Test_Spec_Operations(t *testing.T) {
+	spec := specOf(t, `
+openapi: 3.0.0
+info: {title: Zoo, version: 1.0.0}
+paths:
+  /zebras/{id}:
+    summary: A zebra
+    servers: [{url: https://zebras.example.com}]
+    parameters:
+      - {name: id, in: path, required: true, description: The zebra}
+      - {name: trace, in: header}
+    put:
+      operationId: updateZebra
+      tags: [zebras]
+      parameters:
+        - {name: id, in: path, required: true, description: The zebra to update}
+        - {name: dry-run, in: query}
+    get:
+      operationId: getZebra
+      tags: [zebras, read]
+  /apes:
+    get:
+      operationId: listApes
+      tags: [apes, read]
+`)
+	spec.KeyOrder = model.KeyOrder{"/paths": {"/zebras/{id}", "/apes"}}
+
+	var got []string
+	for op := range spec.Operations() {
+		got = append(got, op.Method+" "+op.Path+" "+op.Operation.OperationID)
+	}
+	require.Equal(t, []string{"get /zebras/{id} getZebra", "put /zebras/{id} updateZebra", "get /apes listApes"}, got)
+
+	update, ok := spec.FindOperation("updateZebra")
+	require.True(t, ok)
+	require.Equal(t, "put", update.Method)
+	require.Equal(t, []string{"zebras"}, update.Operation.Tags)
+	require.Len(t, update.Parameters, 3)
+	require.Equal(t, "The zebra to update", update.Parameters[0].Description)
+	require.Equal(t, "trace", update.Parameters[1].Name)
+	require.Equal(t, "dry-run", update.Parameters[2].Name)
+
+	get, ok := spec.FindOperation("getZebra")
+	require.True(t, ok)
+	require.Equal(t, "The zebra", get.Parameters[0].Description)
+
+	_, ok = spec.FindOperation("feedZebra")
+	require.False(t, ok)
+
+	read := spec.OperationsByTag("read")
+	require.Len(t, read, 2)
+	require.Equal(t, "getZebra", read[0].Operation.OperationID)
+	require.Equal(t, "listApes", read[1].Operation.OperationID)
+	require.Empty(t, spec.OperationsByTag("lions"))
+
+	require.ElementsMatch(t, []model.DocumentedPath{
+		{Path: "/zebras/{id}", Method: "get"},
+		{Path: "/zebras/{id}", Method: "put"},
+		{Path: "/apes", Method: "get"},
+	}, spec.DocumentedPaths())
+}
+
+func Test_Spec_Operations_InvalidParts(t *testing.T) {
+	spec := specOf(t, `
+openapi: 3.0.0
+info: {title: Zoo, version: 1.0.0}
+paths:
+  /zebras:
+    parameters: invalid
+    get:
+      operationId: listZebras
+      tags: invalid
+    post:
+      operationId: addZebra
+  /apes:
+    get: {}
+`)
+	spec.KeyOrder = model.KeyOrder{"/paths": {"/zebras", "/apes"}}
+
+	var got []string
+	for op := range spec.Operations() {
+		got = append(got, op.Method+" "+op.Path+" "+op.Operation.OperationID)
+	}
+	require.Equal(t, []string{"get /zebras listZebras", "post /zebras addZebra", "get /apes "}, got)
+
+	_, ok := spec.FindOperation("addZebra")
+	require.True(t, ok)
+	_, ok = spec.FindOperation("")
+	require.False(t, ok)
+}
@@ -0,0 +1,176 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// KeyOrder holds the order of the keys of the objects of a spec as written in its source files, by the
+// JSON pointer of the object. Keys it does not hold, e.g. added by a spec modifier, follow in alphabetical order.
+type KeyOrder map[string][]string
+
+// Keys returns the keys of the object at the pointer in order
+func (o KeyOrder) Keys(pointer string, obj GenericObject) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	return o.sort(pointer, keys)
+}
+
+// Append adds the keys missing from the order of the object at the pointer after the others
+func (o KeyOrder) Append(pointer string, keys ...string) {
+	for _, key := range keys {
+		if !slices.Contains(o[pointer], key) {
+			o[pointer] = append(slices.Clip(o[pointer]), key)
+		}
+	}
+}
+
+// Copy replaces the order of the object at the pointer and of the objects within with the order of
+// the object at from in the source, used when a value of the source is placed at the pointer
+func (o KeyOrder) Copy(pointer string, source KeyOrder, from string) {
+	copied := KeyOrder{}
+	for at, keys := range source {
+		if rest, ok := within(at, from); ok {
+			copied[pointer+rest] = keys
+		}
+	}
+
+	for at := range o {
+		if _, ok := within(at, pointer); ok {
+			delete(o, at)
+		}
+	}
+	for at, keys := range copied {
+		o[at] = keys
+	}
+}
+
+// sort returns the keys of the object at the pointer in order
+func (o KeyOrder) sort(pointer string, keys []string) []string {
+	slices.Sort(keys)
+	ordered, ok := o[pointer]
+	if !ok {
+		return keys
+	}
+
+	res := make([]string, 0, len(keys))
+	for _, key := range ordered {
+		if _, found := slices.BinarySearch(keys, key); found && !slices.Contains(res, key) {
+			res = append(res, key)
+		}
+	}
+	for _, key := range keys {
+		if !slices.Contains(res, key) {
+			res = append(res, key)
+		}
+	}
+	return res
+}
+
+// within returns the rest of the pointer after the parent pointer, when the pointer is the parent or within it
+func within(pointer, parent string) (string, bool) {
+	rest, ok := strings.CutPrefix(pointer, parent)
+	return rest, ok && (rest == "" || rest[0] == '/')
+}
+
+// apply returns the JSON data with the keys of the objects in order, the other objects are left as they are
+func (o KeyOrder) apply(data []byte) ([]byte, error) {
+	parents := map[string]bool{}
+	for pointer := range o {
+		for ; pointer != ""; pointer = pointer[:strings.LastIndex(pointer, "/")] {
+			parents[pointer] = true
+		}
+		parents[""] = true
+	}
+
+	var buf bytes.Buffer
+	if err := o.write(&buf, data, "", parents); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// write writes the JSON value at the pointer to buf, parents holds the pointers of the objects holding ordered ones
+func (o KeyOrder) write(buf *bytes.Buffer, value json.RawMessage, pointer string, parents map[string]bool) error {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 || !parents[pointer] || (value[0] != '{' && value[0] != '[') {
+		buf.Write(value)
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(value))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	if value[0] == '[' {
+		buf.WriteByte('[')
+		for i := 0; dec.More(); i++ {
+			var item json.RawMessage
+			if err := dec.Decode(&item); err != nil {
+				return err
+			}
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := o.write(buf, item, pointer+"/"+strconv.Itoa(i), parents); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+
+	var keys []string
+	items := map[string]json.RawMessage{}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := token.(string)
+
+		var item json.RawMessage
+		if err := dec.Decode(&item); err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		items[key] = item
+	}
+	if _, ok := o[pointer]; ok {
+		keys = o.sort(pointer, keys)
+	}
+
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		if err := o.write(buf, items[key], pointer+"/"+EscapeToken(key), parents); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// EscapeToken escapes a key to be used as a JSON pointer token
+func EscapeToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// UnescapeToken reverts EscapeToken
+func UnescapeToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
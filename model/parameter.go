@@ -0,0 +1,27 @@
+package model
+
+// Parameter describes a single operation parameter, a header or a reference to one with Ref
+type Parameter struct {
+	Ref             string               `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Name            string               `json:"name,omitempty" yaml:"name,omitempty"`
+	In              string               `json:"in,omitempty" yaml:"in,omitempty"`
+	Description     string               `json:"description,omitempty" yaml:"description,omitempty"`
+	Required        bool                 `json:"required,omitempty" yaml:"required,omitempty"`
+	Deprecated      bool                 `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	AllowEmptyValue bool                 `json:"allowEmptyValue,omitempty" yaml:"allowEmptyValue,omitempty"`
+	Style           string               `json:"style,omitempty" yaml:"style,omitempty"`
+	Explode         *bool                `json:"explode,omitempty" yaml:"explode,omitempty"`
+	AllowReserved   bool                 `json:"allowReserved,omitempty" yaml:"allowReserved,omitempty"`
+	Schema          *Schema              `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example         any                  `json:"example,omitempty" yaml:"example,omitempty"`
+	Examples        GenericObject        `json:"examples,omitempty" yaml:"examples,omitempty"`
+	Content         map[string]MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+	// Extensions holds the x- extensions and any other field the parameter has no field for
+	Extensions GenericObject `json:"-" yaml:",inline"`
+}
+
+// MarshalJSON returns the JSON encoding of the parameter followed by the extensions
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	type parameter Parameter
+	return marshalWithExtensions(parameter(p), p.Extensions)
+}
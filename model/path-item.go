@@ -0,0 +1,86 @@
+package model
+
+// HTTPMethods are the keys of a path item holding an operation, in the order the operations are listed
+var HTTPMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// PathItem describes the operations available on a single path, see Spec.PathItem
+type PathItem struct {
+	Ref         string      `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Summary     string      `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Get         *Operation  `json:"get,omitempty" yaml:"get,omitempty"`
+	Put         *Operation  `json:"put,omitempty" yaml:"put,omitempty"`
+	Post        *Operation  `json:"post,omitempty" yaml:"post,omitempty"`
+	Delete      *Operation  `json:"delete,omitempty" yaml:"delete,omitempty"`
+	Options     *Operation  `json:"options,omitempty" yaml:"options,omitempty"`
+	Head        *Operation  `json:"head,omitempty" yaml:"head,omitempty"`
+	Patch       *Operation  `json:"patch,omitempty" yaml:"patch,omitempty"`
+	Trace       *Operation  `json:"trace,omitempty" yaml:"trace,omitempty"`
+	Servers     []Server    `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Parameters  []Parameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	// Extensions holds the x- extensions and any other field the path item has no field for
+	Extensions GenericObject `json:"-" yaml:",inline"`
+}
+
+// Operation returns the operation of the path item for the HTTP method in lower case, nil when there is none
+func (p PathItem) Operation(method string) *Operation {
+	switch method {
+	case "get":
+		return p.Get
+	case "put":
+		return p.Put
+	case "post":
+		return p.Post
+	case "delete":
+		return p.Delete
+	case "options":
+		return p.Options
+	case "head":
+		return p.Head
+	case "patch":
+		return p.Patch
+	case "trace":
+		return p.Trace
+	default:
+		return nil
+	}
+}
+
+// Operation describes a single API operation on a path
+type Operation struct {
+	Tags         []string               `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Summary      string                 `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description  string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	ExternalDocs *ExternalDocumentation `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+	OperationID  string                 `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Parameters   []Parameter            `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody  *RequestBody           `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses    map[string]Response    `json:"responses,omitempty" yaml:"responses,omitempty"`
+	Callbacks    GenericObject          `json:"callbacks,omitempty" yaml:"callbacks,omitempty"`
+	Deprecated   bool                   `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	// Security overrides the security of the spec, an empty list removes it while nil keeps it
+	Security []SecurityRequirement `json:"security" yaml:"security,omitempty"`
+	Servers  []Server              `json:"servers,omitempty" yaml:"servers,omitempty"`
+	// Extensions holds the x- extensions and any other field the operation has no field for
+	Extensions GenericObject `json:"-" yaml:",inline"`
+}
+
+// MarshalJSON returns the JSON encoding of the path item followed by the extensions
+func (p PathItem) MarshalJSON() ([]byte, error) {
+	type pathItem PathItem
+	return marshalWithExtensions(pathItem(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of the operation followed by the extensions,
+// security is left out when nil
+func (o Operation) MarshalJSON() ([]byte, error) {
+	type operation Operation
+	value := struct {
+		operation
+		Security *[]SecurityRequirement `json:"security,omitempty"`
+	}{operation: operation(o)}
+	if o.Security != nil {
+		value.Security = &o.Security
+	}
+	return marshalWithExtensions(value, o.Extensions)
+}
@@ -0,0 +1,50 @@
+package model
+
+// RequestBody describes the body of a request, or a reference to one with Ref
+type RequestBody struct {
+	Ref         string               `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Description string               `json:"description,omitempty" yaml:"description,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+	Required    bool                 `json:"required,omitempty" yaml:"required,omitempty"`
+	// Extensions holds the x- extensions and any other field the request body has no field for
+	Extensions GenericObject `json:"-" yaml:",inline"`
+}
+
+// Response describes a single response of an operation, or a reference to one with Ref
+type Response struct {
+	Ref         string               `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Description string               `json:"description,omitempty" yaml:"description,omitempty"`
+	Headers     map[string]Parameter `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+	Links       GenericObject        `json:"links,omitempty" yaml:"links,omitempty"`
+	// Extensions holds the x- extensions and any other field the response has no field for
+	Extensions GenericObject `json:"-" yaml:",inline"`
+}
+
+// MediaType describes the schema and examples of a content type
+type MediaType struct {
+	Schema   *Schema       `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example  any           `json:"example,omitempty" yaml:"example,omitempty"`
+	Examples GenericObject `json:"examples,omitempty" yaml:"examples,omitempty"`
+	Encoding GenericObject `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	// Extensions holds the x- extensions and any other field the media type has no field for
+	Extensions GenericObject `json:"-" yaml:",inline"`
+}
+
+// MarshalJSON returns the JSON encoding of the request body followed by the extensions
+func (b RequestBody) MarshalJSON() ([]byte, error) {
+	type requestBody RequestBody
+	return marshalWithExtensions(requestBody(b), b.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of the response followed by the extensions
+func (r Response) MarshalJSON() ([]byte, error) {
+	type response Response
+	return marshalWithExtensions(response(r), r.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of the media type followed by the extensions
+func (m MediaType) MarshalJSON() ([]byte, error) {
+	type mediaType MediaType
+	return marshalWithExtensions(mediaType(m), m.Extensions)
+}
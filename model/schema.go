@@ -0,0 +1,144 @@
+package model
+
+import (
+	"encoding/json"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema describes a data type, with the keywords of OpenAPI 3.0 and of the JSON Schema of OpenAPI 3.1
+type Schema struct {
+	// Bool is set for the boolean schemas of OpenAPI 3.1, true allows any value and false none,
+	// the other fields are empty then
+	Bool        *bool      `json:"-" yaml:"-"`
+	Ref         string     `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Title       string     `json:"title,omitempty" yaml:"title,omitempty"`
+	Description string     `json:"description,omitempty" yaml:"description,omitempty"`
+	Type        SchemaType `json:"type,omitempty" yaml:"type,omitempty"`
+	Format      string     `json:"format,omitempty" yaml:"format,omitempty"`
+	Enum        []any      `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Const       any        `json:"const,omitempty" yaml:"const,omitempty"`
+	Default     any        `json:"default,omitempty" yaml:"default,omitempty"`
+	// Nullable is the OpenAPI 3.0 way to allow null, OpenAPI 3.1 adds "null" to the Type
+	Nullable   bool `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	ReadOnly   bool `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+	WriteOnly  bool `json:"writeOnly,omitempty" yaml:"writeOnly,omitempty"`
+	Deprecated bool `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Example    any  `json:"example,omitempty" yaml:"example,omitempty"`
+	// Examples is the OpenAPI 3.1 list of examples
+	Examples   []any    `json:"examples,omitempty" yaml:"examples,omitempty"`
+	MultipleOf *float64 `json:"multipleOf,omitempty" yaml:"multipleOf,omitempty"`
+	Maximum    *float64 `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	// ExclusiveMaximum is a bool in OpenAPI 3.0 and a number in OpenAPI 3.1
+	ExclusiveMaximum any      `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty"`
+	Minimum          *float64 `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	// ExclusiveMinimum is a bool in OpenAPI 3.0 and a number in OpenAPI 3.1
+	ExclusiveMinimum any                `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty"`
+	MaxLength        *int               `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	MinLength        *int               `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	Pattern          string             `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Items            *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	PrefixItems      []*Schema          `json:"prefixItems,omitempty" yaml:"prefixItems,omitempty"`
+	MaxItems         *int               `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+	MinItems         *int               `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	UniqueItems      bool               `json:"uniqueItems,omitempty" yaml:"uniqueItems,omitempty"`
+	MaxProperties    *int               `json:"maxProperties,omitempty" yaml:"maxProperties,omitempty"`
+	MinProperties    *int               `json:"minProperties,omitempty" yaml:"minProperties,omitempty"`
+	Required         []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	Properties       map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	// AdditionalProperties is either a bool or a schema object
+	AdditionalProperties any                    `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	AllOf                []*Schema              `json:"allOf,omitempty" yaml:"allOf,omitempty"`
+	AnyOf                []*Schema              `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+	OneOf                []*Schema              `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	Not                  *Schema                `json:"not,omitempty" yaml:"not,omitempty"`
+	Discriminator        GenericObject          `json:"discriminator,omitempty" yaml:"discriminator,omitempty"`
+	XML                  GenericObject          `json:"xml,omitempty" yaml:"xml,omitempty"`
+	ExternalDocs         *ExternalDocumentation `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+	// Extensions holds the x- extensions and any other keyword the schema has no field for
+	Extensions GenericObject `json:"-" yaml:",inline"`
+}
+
+// SchemaType holds the type of a schema, written as a single type when it holds one and as a list otherwise
+type SchemaType []string
+
+// MarshalJSON returns the JSON encoding of the schema followed by the extensions, or the bool of a boolean schema
+func (s Schema) MarshalJSON() ([]byte, error) {
+	if s.Bool != nil {
+		return json.Marshal(*s.Bool)
+	}
+	type schema Schema
+	return marshalWithExtensions(schema(s), s.Extensions)
+}
+
+// UnmarshalJSON reads the schema from a schema object or from the bool of a boolean schema
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*s = Schema{Bool: &b}
+		return nil
+	}
+	type schema Schema
+	return json.Unmarshal(data, (*schema)(s))
+}
+
+// MarshalYAML returns the schema, or the bool of a boolean schema
+func (s Schema) MarshalYAML() (any, error) {
+	if s.Bool != nil {
+		return *s.Bool, nil
+	}
+	type schema Schema
+	return schema(s), nil
+}
+
+// UnmarshalYAML reads the schema from a schema object or from the bool of a boolean schema
+func (s *Schema) UnmarshalYAML(node *yaml.Node) error {
+	var b bool
+	if node.Kind == yaml.ScalarNode && node.Decode(&b) == nil {
+		*s = Schema{Bool: &b}
+		return nil
+	}
+	type schema Schema
+	return node.Decode((*schema)(s))
+}
+
+// Is checks if the type is one of the types
+func (t SchemaType) Is(typ string) bool {
+	return slices.Contains(t, typ)
+}
+
+// MarshalJSON returns the type as a string when it holds one and as a list otherwise
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON reads the type from a string or a list of strings
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var typ string
+	if err := json.Unmarshal(data, &typ); err == nil {
+		*t = SchemaType{typ}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// MarshalYAML returns the type as a string when it holds one and as a list otherwise
+func (t SchemaType) MarshalYAML() (any, error) {
+	if len(t) == 1 {
+		return t[0], nil
+	}
+	return []string(t), nil
+}
+
+// UnmarshalYAML reads the type from a string or a list of strings
+func (t *SchemaType) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = SchemaType{node.Value}
+		return nil
+	}
+	return node.Decode((*[]string)(t))
+}
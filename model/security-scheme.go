@@ -0,0 +1,22 @@
+package model
+
+// SecurityScheme describes a security scheme the operations can use, or a reference to one with Ref
+type SecurityScheme struct {
+	Ref              string        `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type             string        `json:"type,omitempty" yaml:"type,omitempty"`
+	Description      string        `json:"description,omitempty" yaml:"description,omitempty"`
+	Name             string        `json:"name,omitempty" yaml:"name,omitempty"`
+	In               string        `json:"in,omitempty" yaml:"in,omitempty"`
+	Scheme           string        `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+	BearerFormat     string        `json:"bearerFormat,omitempty" yaml:"bearerFormat,omitempty"`
+	Flows            GenericObject `json:"flows,omitempty" yaml:"flows,omitempty"`
+	OpenIDConnectURL string        `json:"openIdConnectUrl,omitempty" yaml:"openIdConnectUrl,omitempty"`
+	// Extensions holds the x- extensions and any other field the security scheme has no field for
+	Extensions GenericObject `json:"-" yaml:",inline"`
+}
+
+// MarshalJSON returns the JSON encoding of the security scheme followed by the extensions
+func (s SecurityScheme) MarshalJSON() ([]byte, error) {
+	type securityScheme SecurityScheme
+	return marshalWithExtensions(securityScheme(s), s.Extensions)
+}
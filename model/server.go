@@ -5,11 +5,26 @@ type Server struct {
 	URL         string                    `json:"url" yaml:"url"`
 	Description *string                   `json:"description,omitempty" yaml:"description,omitempty"`
 	Variables   map[string]ServerVariable `json:"variables,omitempty" yaml:"variables,omitempty"`
+	// Extensions holds the x- extensions and any other field the server has no field for
+	Extensions GenericObject `json:"-" yaml:",inline"`
 }
 
 // ServerVariable structure is generated from "#/$defs/server-variable".
 type ServerVariable struct {
-	Enum        []string `json:"enum,omitempty" yaml:"enum,omitempty"`
-	Default     string   `json:"default" yaml:"default"`
-	Description *string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Enum        []string      `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Default     string        `json:"default" yaml:"default"`
+	Description *string       `json:"description,omitempty" yaml:"description,omitempty"`
+	Extensions  GenericObject `json:"-" yaml:",inline"`
+}
+
+// MarshalJSON returns the JSON encoding of the server followed by the extensions
+func (s Server) MarshalJSON() ([]byte, error) {
+	type server Server
+	return marshalWithExtensions(server(s), s.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of the server variable followed by the extensions
+func (v ServerVariable) MarshalJSON() ([]byte, error) {
+	type serverVariable ServerVariable
+	return marshalWithExtensions(serverVariable(v), v.Extensions)
 }
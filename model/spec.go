@@ -1,6 +1,12 @@
 package model
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
 
 // GenericObject represets the generic yaml or json object where key is always string and value can be anything
 type GenericObject map[string]any
@@ -11,24 +17,99 @@ type DocumentedPath struct {
 	Method string
 }
 
+// SecurityRequirement lists the scopes required by each security scheme, by the name of the scheme
+type SecurityRequirement map[string][]string
+
 // Spec represents the OpenAPI spec definition
 type Spec struct {
-	OpenAPI    string        `yaml:"openapi" json:"openapi"`
-	Info       Info          `yaml:"info" json:"info"`
-	Paths      GenericObject `yaml:"paths" json:"paths"`
-	Servers    []Server      `yaml:"servers" json:"servers"`
-	Tags       []Tag         `yaml:"tags" json:"tags"`
-	TagsGroup  []TagGroup    `yaml:"x-tagGroups" json:"x-tagGroups"`
-	Components Components    `yaml:"components" json:"components"`
+	OpenAPI           string                 `yaml:"openapi,omitempty" json:"openapi,omitempty"`
+	Info              Info                   `yaml:"info,omitempty" json:"info"`
+	JSONSchemaDialect string                 `yaml:"jsonSchemaDialect,omitempty" json:"jsonSchemaDialect,omitempty"`
+	Servers           []Server               `yaml:"servers,omitempty" json:"servers,omitempty"`
+	Paths             GenericObject          `yaml:"paths,omitempty" json:"paths,omitempty"`
+	Webhooks          GenericObject          `yaml:"webhooks,omitempty" json:"webhooks,omitempty"`
+	Components        Components             `yaml:"components,omitempty" json:"components"`
+	Security          []SecurityRequirement  `yaml:"security,omitempty" json:"security,omitempty"`
+	Tags              []Tag                  `yaml:"tags,omitempty" json:"tags,omitempty"`
+	TagsGroup         []TagGroup             `yaml:"x-tagGroups,omitempty" json:"x-tagGroups,omitempty"`
+	ExternalDocs      *ExternalDocumentation `yaml:"externalDocs,omitempty" json:"externalDocs,omitempty"`
+	// Extensions holds the x- extensions and any other field the spec has no field for
+	Extensions GenericObject `yaml:",inline" json:"-"`
+	// KeyOrder keeps the order of the keys of the paths, webhooks and components as written in the source files
+	KeyOrder KeyOrder `yaml:"-" json:"-"`
+}
+
+// MarshalJSON returns the JSON encoding of the spec with the keys of the objects in KeyOrder in order,
+// info and components are left out when empty
+func (s Spec) MarshalJSON() ([]byte, error) {
+	type spec Spec
+	value := struct {
+		OpenAPI string `json:"openapi,omitempty"`
+		Info    *Info  `json:"info,omitempty"`
+		spec
+		Components *Components `json:"components,omitempty"`
+	}{OpenAPI: s.OpenAPI, spec: spec(s)}
+	if !reflect.ValueOf(s.Info).IsZero() {
+		value.Info = &s.Info
+	}
+	if !s.Components.isEmpty() {
+		value.Components = &s.Components
+	}
+
+	data, err := marshalWithExtensions(value, s.Extensions)
+	if err != nil || len(s.KeyOrder) == 0 {
+		return data, err
+	}
+	return s.KeyOrder.apply(data)
+}
+
+// MarshalYAML returns the spec as a YAML node with the keys of the objects in KeyOrder in order,
+// the node is built from the JSON encoding of the spec, see MarshalJSON
+func (s Spec) MarshalYAML() (any, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	root := document.Content[0]
+	blockStyle(root)
+	return root, nil
+}
+
+// blockStyle clears the JSON flow and quoting styles of the node and the nodes within, the encoder
+// writes them as block YAML and quotes the strings that need it
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// UnmarshalJSON reads the spec from JSON, the fields the spec and its typed objects have no field for
+// are kept in their Extensions
+func (s *Spec) UnmarshalJSON(data []byte) error {
+	type spec Spec
+	if err := json.Unmarshal(data, (*spec)(s)); err != nil {
+		return err
+	}
+
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	setExtensions(reflect.ValueOf(s).Elem(), raw)
+	return nil
 }
 
-// DocumentedPaths returns the list of path in the spec
+// DocumentedPaths returns the path and HTTP method of the operations in the spec, see Operations
 func (s Spec) DocumentedPaths() []DocumentedPath {
 	paths := make([]DocumentedPath, 0)
-	for path, methods := range s.Paths {
-		for method := range methods.(GenericObject) {
-			paths = append(paths, DocumentedPath{Path: path, Method: method})
-		}
+	for op := range s.Operations() {
+		paths = append(paths, DocumentedPath{Path: op.Path, Method: op.Method})
 	}
 	return paths
 }
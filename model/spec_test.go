@@ -0,0 +1,37 @@
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+func Test_Spec_MarshalYAML(t *testing.T) {
+	spec := specOf(t, `
+openapi: 3.0.0
+info: {title: Zoo, version: 1.0.0}
+paths:
+  /zebras:
+    get: {summary: "200", description: "multi\nline"}
+  /apes:
+    get: {summary: Apes}
+`)
+	spec.KeyOrder = model.KeyOrder{"/paths": {"/zebras", "/apes"}}
+
+	data, err := yaml.Marshal(spec)
+	require.NoError(t, err)
+
+	content := string(data)
+	require.Less(t, strings.Index(content, "/zebras:"), strings.Index(content, "/apes:"), content)
+	require.NotContains(t, content, "{", "objects must be written in block style")
+
+	var reloaded model.Spec
+	require.NoError(t, yaml.Unmarshal(data, &reloaded))
+	zebras := reloaded.Paths["/zebras"].(model.GenericObject)["get"].(model.GenericObject)
+	require.Equal(t, "200", zebras["summary"])
+	require.Equal(t, "multi\nline", zebras["description"])
+}
@@ -3,6 +3,6 @@ package model
 // TagGroup represent the element of the x-tagGroups
 type TagGroup struct {
 	Name        string   `yaml:"name" json:"name"`
-	Description string   `yaml:"description" json:"description"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
 	Tags        []string `yaml:"tags" json:"tags"`
 }
@@ -5,10 +5,25 @@ type Tag struct {
 	Name         string                 `json:"name" yaml:"name"`
 	Description  string                 `json:"description,omitempty" yaml:"description,omitempty"`
 	ExternalDocs *ExternalDocumentation `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+	// Extensions holds the x- extensions and any other field the tag has no field for
+	Extensions GenericObject `json:"-" yaml:",inline"`
 }
 
 // ExternalDocumentation structure is generated from "#/$defs/external-documentation".
 type ExternalDocumentation struct {
-	Description *string `json:"description,omitempty" yaml:"description,omitempty"`
-	URL         string  `json:"url" yaml:"url"`
+	Description *string       `json:"description,omitempty" yaml:"description,omitempty"`
+	URL         string        `json:"url" yaml:"url"`
+	Extensions  GenericObject `json:"-" yaml:",inline"`
+}
+
+// MarshalJSON returns the JSON encoding of the tag followed by the extensions
+func (t Tag) MarshalJSON() ([]byte, error) {
+	type tag Tag
+	return marshalWithExtensions(tag(t), t.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of the external documentation followed by the extensions
+func (d ExternalDocumentation) MarshalJSON() ([]byte, error) {
+	type externalDocumentation ExternalDocumentation
+	return marshalWithExtensions(externalDocumentation(d), d.Extensions)
 }
@@ -0,0 +1,182 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrNotFound is returned when the spec has no entry by the name asked for
+var ErrNotFound = errors.New("not found")
+
+// PathItem returns the typed path item of the path, the spec is left untouched
+func (s Spec) PathItem(path string) (*PathItem, error) {
+	return typedEntry[PathItem](s.Paths, "path", path)
+}
+
+// SetPathItem replaces the path item of the path, or adds it after the other paths
+func (s *Spec) SetPathItem(path string, item *PathItem) error {
+	return s.setEntry(&s.Paths, "/paths", path, item)
+}
+
+// Schema returns the typed schema of components.schemas by its name
+func (s Spec) Schema(name string) (*Schema, error) {
+	return typedEntry[Schema](s.Components.Schemas, "schema", name)
+}
+
+// SetSchema replaces the schema of components.schemas by its name, or adds it after the others
+func (s *Spec) SetSchema(name string, schema *Schema) error {
+	return s.setEntry(&s.Components.Schemas, "/components/schemas", name, schema)
+}
+
+// Parameter returns the typed parameter of components.parameters by its name
+func (s Spec) Parameter(name string) (*Parameter, error) {
+	return typedEntry[Parameter](s.Components.Parameters, "parameter", name)
+}
+
+// SetParameter replaces the parameter of components.parameters by its name, or adds it after the others
+func (s *Spec) SetParameter(name string, parameter *Parameter) error {
+	return s.setEntry(&s.Components.Parameters, "/components/parameters", name, parameter)
+}
+
+// RequestBody returns the typed request body of components.requestBodies by its name
+func (s Spec) RequestBody(name string) (*RequestBody, error) {
+	return typedEntry[RequestBody](s.Components.RequestBodies, "request body", name)
+}
+
+// SetRequestBody replaces the request body of components.requestBodies by its name, or adds it after the others
+func (s *Spec) SetRequestBody(name string, body *RequestBody) error {
+	return s.setEntry(&s.Components.RequestBodies, "/components/requestBodies", name, body)
+}
+
+// Response returns the typed response of components.responses by its name
+func (s Spec) Response(name string) (*Response, error) {
+	return typedEntry[Response](s.Components.Responses, "response", name)
+}
+
+// SetResponse replaces the response of components.responses by its name, or adds it after the others
+func (s *Spec) SetResponse(name string, response *Response) error {
+	return s.setEntry(&s.Components.Responses, "/components/responses", name, response)
+}
+
+// SecurityScheme returns the typed security scheme of components.securitySchemes by its name
+func (s Spec) SecurityScheme(name string) (*SecurityScheme, error) {
+	return typedEntry[SecurityScheme](s.Components.SecuritySchemes, "security scheme", name)
+}
+
+// SetSecurityScheme replaces the security scheme of components.securitySchemes by its name, or adds it after the others
+func (s *Spec) SetSecurityScheme(name string, scheme *SecurityScheme) error {
+	return s.setEntry(&s.Components.SecuritySchemes, "/components/securitySchemes", name, scheme)
+}
+
+// typedEntry returns the entry of the section by its name as a T
+func typedEntry[T any](section GenericObject, kind string, name string) (*T, error) {
+	value, ok := section[name]
+	if !ok {
+		return nil, fmt.Errorf("%s '%s': %w", kind, name, ErrNotFound)
+	}
+
+	res := new(T)
+	if err := Decode(value, res); err != nil {
+		return nil, fmt.Errorf("%s '%s': %w", kind, name, err)
+	}
+	return res, nil
+}
+
+// setEntry sets the entry of the section by its name to the generic form of the value
+func (s *Spec) setEntry(section *GenericObject, pointer string, name string, value any) error {
+	generic, err := Encode(value)
+	if err != nil {
+		return fmt.Errorf("'%s': %w", name, err)
+	}
+
+	if *section == nil {
+		*section = GenericObject{}
+	}
+	(*section)[name] = generic
+	if s.KeyOrder != nil {
+		s.KeyOrder.Append(pointer, name)
+	}
+	return nil
+}
+
+// Decode reads the generic value, e.g. an entry of Spec.Paths, into the typed object pointed to by v.
+// The fields the typed objects have no field for are kept in their Extensions.
+func Decode(value any, v any) error {
+	data, err := json.Marshal(jsonValue(value))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	setExtensions(reflect.ValueOf(v), raw)
+	return nil
+}
+
+// Encode returns the generic form of the typed object, the objects within are GenericObject
+func Encode(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return genericValue(raw), nil
+}
+
+// jsonValue returns the value with the maps keyed by any, as decoded from YAML, keyed by string instead
+func jsonValue(value any) any {
+	switch v := value.(type) {
+	case map[any]any:
+		res := make(map[string]any, len(v))
+		for key, item := range v {
+			res[fmt.Sprintf("%v", key)] = jsonValue(item)
+		}
+		return res
+	case GenericObject:
+		res := make(map[string]any, len(v))
+		for key, item := range v {
+			res[key] = jsonValue(item)
+		}
+		return res
+	case map[string]any:
+		return jsonValue(GenericObject(v))
+	case []any:
+		res := make([]any, len(v))
+		for i, item := range v {
+			res[i] = jsonValue(item)
+		}
+		return res
+	default:
+		return value
+	}
+}
+
+// genericValue returns the decoded JSON value with the objects as GenericObject
+func genericValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		res := make(GenericObject, len(v))
+		for key, item := range v {
+			res[key] = genericValue(item)
+		}
+		return res
+	case []any:
+		for i, item := range v {
+			v[i] = genericValue(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
@@ -0,0 +1,181 @@
+package model_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+const zoo = `
+openapi: 3.0.0
+info: {title: Zoo, version: 1.0.0}
+paths:
+  /zebras:
+    summary: The zebras
+    parameters:
+      - {name: trace, in: header, x-internal: true}
+    get:
+      summary: List zebras
+      operationId: listZebras
+      tags: [animals]
+      x-rate-limit: 100
+      responses:
+        "200":
+          description: The zebras
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Zebras"
+  /apes:
+    post:
+      operationId: addApe
+      security: [{oauth: ["animals:write"]}]
+      requestBody:
+        content:
+          application/json:
+            schema: {type: object, x-display: ape}
+      responses:
+        "201":
+          description: Created
+          headers:
+            Location: {schema: {type: string}}
+components:
+  schemas:
+    Zebra:
+      type: object
+      properties:
+        stripes: {type: integer}
+  securitySchemes:
+    oauth:
+      type: oauth2
+      flows:
+        clientCredentials:
+          tokenUrl: https://zoo.example.com/oauth/token
+          scopes:
+            animals:write: Add animals
+`
+
+func Test_Spec_TypedObjects(t *testing.T) {
+	spec := specOf(t, zoo)
+
+	zebras, err := spec.PathItem("/zebras")
+	require.NoError(t, err)
+	require.Equal(t, "listZebras", zebras.Get.OperationID)
+	require.Equal(t, []string{"animals"}, zebras.Get.Tags)
+	require.Equal(t, model.GenericObject{"x-rate-limit": float64(100)}, zebras.Get.Extensions)
+	require.Equal(t, "#/components/schemas/Zebras", zebras.Get.Responses["200"].Content["application/json"].Schema.Ref)
+	require.Nil(t, zebras.Get.Security)
+
+	zebra, err := spec.Schema("Zebra")
+	require.NoError(t, err)
+	require.Equal(t, model.SchemaType{"object"}, zebra.Type)
+	require.True(t, zebra.Properties["stripes"].Type.Is("integer"))
+
+	oauth, err := spec.SecurityScheme("oauth")
+	require.NoError(t, err)
+	require.Equal(t, "oauth2", oauth.Type)
+	require.Contains(t, oauth.Flows, "clientCredentials")
+
+	_, err = spec.PathItem("/lions")
+	require.ErrorIs(t, err, model.ErrNotFound)
+	require.EqualError(t, err, "path '/lions': not found")
+}
+
+func Test_Spec_TypedObjects_OpenAPI31(t *testing.T) {
+	spec := specOf(t, `
+openapi: 3.1.0
+info: {title: Zoo, version: 1.0.0}
+components:
+  schemas:
+    Name:
+      type: [string, "null"]
+      examples: [Zebra]
+      exclusiveMinimum: 1
+      x-display: name
+    Closed:
+      type: object
+      properties:
+        name: true
+        secret: false
+      items: false
+paths:
+  /names:
+    get:
+      responses:
+        "200":
+          description: The names
+          content:
+            application/json:
+              schema: {type: array, items: true}
+`)
+
+	schema, err := spec.Schema("Name")
+	require.NoError(t, err)
+	require.Equal(t, model.SchemaType{"string", "null"}, schema.Type)
+	require.Equal(t, []any{"Zebra"}, schema.Examples)
+	require.Equal(t, float64(1), schema.ExclusiveMinimum)
+	require.Equal(t, model.GenericObject{"x-display": "name"}, schema.Extensions)
+
+	closed, err := spec.Schema("Closed")
+	require.NoError(t, err)
+	require.True(t, *closed.Properties["name"].Bool)
+	require.False(t, *closed.Properties["secret"].Bool)
+	require.False(t, *closed.Items.Bool)
+	require.Nil(t, closed.Bool)
+
+	data, err := json.Marshal(closed)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"object","properties":{"name":true,"secret":false},"items":false}`, string(data))
+
+	names, err := spec.PathItem("/names")
+	require.NoError(t, err)
+	require.True(t, *names.Get.Responses["200"].Content["application/json"].Schema.Items.Bool)
+}
+
+func Test_Spec_TypedObjects_Lossless(t *testing.T) {
+	spec := specOf(t, zoo)
+
+	for path, value := range spec.Paths {
+		item, err := spec.PathItem(path)
+		require.NoError(t, err)
+		want, err := json.Marshal(value)
+		require.NoError(t, err)
+
+		require.NoError(t, spec.SetPathItem(path, item))
+		got, err := json.Marshal(spec.Paths[path])
+		require.NoError(t, err)
+		require.JSONEq(t, string(want), string(got), path)
+	}
+}
+
+func Test_Decode(t *testing.T) {
+	var item model.PathItem
+	require.NoError(t, model.Decode(map[string]any{
+		"get": map[string]any{"operationId": "listZebras", "x-rate-limit": 100},
+	}, &item))
+
+	require.Equal(t, "listZebras", item.Get.OperationID)
+	require.Equal(t, model.GenericObject{"x-rate-limit": float64(100)}, item.Get.Extensions)
+}
+
+func Test_Spec_UnmarshalJSON_GenericObjects(t *testing.T) {
+	var spec model.Spec
+	require.NoError(t, json.Unmarshal([]byte(`{"paths":{"/zebras":{"get":{"x-rate-limit":{"max":100}}}},"x-zoo":{"open":true}}`), &spec))
+
+	item := spec.Paths["/zebras"].(model.GenericObject)
+	require.Equal(t, model.GenericObject{"x-rate-limit": model.GenericObject{"max": float64(100)}}, item["get"])
+	require.Equal(t, model.GenericObject{"open": true}, spec.Extensions["x-zoo"])
+}
+
+// specOf returns the spec of the YAML document
+func specOf(t *testing.T, document string) *model.Spec {
+	t.Helper()
+
+	var spec model.Spec
+	require.NoError(t, yaml.Unmarshal([]byte(document), &spec))
+	return &spec
+}
@@ -0,0 +1,19 @@
+package scalargo
+
+import (
+	"context"
+
+	"github.com/bdpiprava/scalar-go/filter"
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+// WithAudience renders the view of the spec for the audience. The operations, tags, parameters and schema
+// properties marked for other audiences with x-audience, or marked with x-internal, are removed with the
+// components only they use, see filter.Apply. The filter runs as a transformer named "audience".
+func WithAudience(audience string, opts ...filter.Option) func(*Options) {
+	opts = append([]filter.Option{filter.ForAudience(audience)}, opts...)
+	return WithTransformers(NewTransformer("audience", func(_ context.Context, spec *model.Spec) error {
+		filter.Apply(spec, opts...)
+		return nil
+	}))
+}
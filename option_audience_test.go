@@ -0,0 +1,50 @@
+package scalargo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	scalargo "github.com/bdpiprava/scalar-go"
+	"github.com/bdpiprava/scalar-go/filter"
+)
+
+func Test_NewV2_WithAudience(t *testing.T) {
+	testCases := []struct {
+		name       string
+		option     scalargo.Option
+		want       []string
+		wantAbsent []string
+	}{
+		{
+			name:       "public",
+			option:     scalargo.WithAudience("public"),
+			want:       []string{`"listAnimals"`, `"Documented"`},
+			wantAbsent: []string{`"feedAnimal"`, `"listPartners"`, `"reset"`, `"Feeding"`, `"Secret"`, `"partnerKey"`, `"cost"`},
+		},
+		{
+			name:       "partner without animals",
+			option:     scalargo.WithAudience("partner", filter.ExcludeTags("animals")),
+			want:       []string{`"feedAnimal"`, `"Feeding"`},
+			wantAbsent: []string{`"listAnimals"`, `"listPartners"`, `"Animal"`, `"partnerKey"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := scalargo.NewV2(
+				scalargo.WithSpecDir("./data/audience"),
+				tc.option,
+			)
+			require.NoError(t, err)
+
+			spec := parseContent(content).spec
+			for _, want := range tc.want {
+				require.Contains(t, spec, want)
+			}
+			for _, absent := range tc.wantAbsent {
+				require.NotContains(t, spec, absent)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package scalargo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bdpiprava/scalar-go/loader"
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+const (
+	// defaultFetchTimeout is the time allowed to fetch the spec from SpecURL
+	defaultFetchTimeout = 10 * time.Second
+	// defaultFetchMaxSize is the largest spec fetched from SpecURL, in bytes
+	defaultFetchMaxSize = 10 << 20
+)
+
+// FetchOptions configure how the spec is fetched from SpecURL on the server
+type FetchOptions struct {
+	Client          *http.Client
+	Timeout         time.Duration
+	MaxSize         int64
+	RefreshInterval time.Duration
+}
+
+type FetchOption func(*FetchOptions)
+
+// WithSpecFetchOpts fetches the spec from SpecURL on the server and renders it inline, so the spec goes
+// through the transformers and the title is taken from it like a spec loaded from a directory.
+// When a refresh interval is set the spec is fetched again in the background once it has passed, and the last
+// successfully fetched spec is kept when fetching fails.
+func WithSpecFetchOpts(opts ...FetchOption) func(*Options) {
+	fetch := &FetchOptions{
+		Client:  http.DefaultClient,
+		Timeout: defaultFetchTimeout,
+		MaxSize: defaultFetchMaxSize,
+	}
+	for _, opt := range opts {
+		opt(fetch)
+	}
+
+	return func(o *Options) {
+		o.Fetch = fetch
+	}
+}
+
+// WithFetchClient sets the http.Client used to fetch the spec
+func WithFetchClient(client *http.Client) FetchOption {
+	return func(o *FetchOptions) {
+		o.Client = client
+	}
+}
+
+// WithFetchTimeout sets the time allowed to fetch the spec
+func WithFetchTimeout(timeout time.Duration) FetchOption {
+	return func(o *FetchOptions) {
+		o.Timeout = timeout
+	}
+}
+
+// WithFetchMaxSize sets the largest spec accepted, in bytes
+func WithFetchMaxSize(maxSize int64) FetchOption {
+	return func(o *FetchOptions) {
+		o.MaxSize = maxSize
+	}
+}
+
+// WithFetchRefreshInterval sets how long a fetched spec is used before it is fetched again
+func WithFetchRefreshInterval(interval time.Duration) FetchOption {
+	return func(o *FetchOptions) {
+		o.RefreshInterval = interval
+	}
+}
+
+// fetchesSpec reports whether the spec is fetched from SpecURL on the server
+func (o *Options) fetchesSpec() bool {
+	return o.Fetch != nil && strings.TrimSpace(o.SpecURL) != ""
+}
+
+// fetchSpec fetches the spec from SpecURL and parses it
+func (o *Options) fetchSpec(ctx context.Context) (*model.Spec, error) {
+	specURL := strings.TrimSpace(o.SpecURL)
+	body, err := o.Fetch.fetch(ctx, specURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching spec from '%s': %w", specURL, err)
+	}
+
+	spec, err := loader.LoadFromBytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("fetching spec from '%s': %w", specURL, err)
+	}
+	return spec, nil
+}
+
+// fetch returns the body of the URL, failing when it is larger than MaxSize
+func (f *FetchOptions) fetch(ctx context.Context, url string) ([]byte, error) {
+	if f.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json, application/yaml;q=0.9, */*;q=0.8")
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	body := io.Reader(resp.Body)
+	if f.MaxSize > 0 {
+		body = io.LimitReader(resp.Body, f.MaxSize+1)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if f.MaxSize > 0 && int64(len(content)) > f.MaxSize {
+		return nil, fmt.Errorf("spec is larger than %d bytes", f.MaxSize)
+	}
+	return content, nil
+}
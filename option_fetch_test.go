@@ -0,0 +1,200 @@
+package scalargo_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	scalargo "github.com/bdpiprava/scalar-go"
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+func Test_NewV2_WithSpecFetchOpts(t *testing.T) {
+	petStore, err := os.ReadFile("./data/loader/pet-store.yml")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		handler   http.HandlerFunc
+		fetchOpts []scalargo.FetchOption
+		want      string
+		wantError string
+	}{
+		{
+			name:    "should inline fetched spec",
+			handler: func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write(petStore) },
+			want:    "<title>Swagger Petstore</title>",
+		},
+		{
+			name:      "should fail when spec is larger than the size limit",
+			handler:   func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write(petStore) },
+			fetchOpts: []scalargo.FetchOption{scalargo.WithFetchMaxSize(10)},
+			wantError: "spec is larger than 10 bytes",
+		},
+		{
+			name:      "should fail on unexpected status",
+			handler:   func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNotFound) },
+			wantError: "unexpected status 404 Not Found",
+		},
+		{
+			name: "should fail when fetching takes longer than the timeout",
+			handler: func(_ http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			},
+			fetchOpts: []scalargo.FetchOption{scalargo.WithFetchTimeout(10 * time.Millisecond)},
+			wantError: "context deadline exceeded",
+		},
+		{
+			name: "should fetch with the configured client",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_, _ = w.Write(petStore)
+			},
+			fetchOpts: []scalargo.FetchOption{scalargo.WithFetchClient(&http.Client{Transport: bearerTransport("token")})},
+			want:      "<title>Swagger Petstore</title>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+
+			content, err := scalargo.NewV2(
+				scalargo.WithSpecURL(server.URL+"/openapi.yaml"),
+				scalargo.WithSpecFetchOpts(tc.fetchOpts...),
+			)
+
+			if tc.wantError != "" {
+				require.ErrorContains(t, err, "fetching spec from '"+server.URL+"/openapi.yaml': ")
+				require.ErrorContains(t, err, tc.wantError)
+				require.Empty(t, content)
+				return
+			}
+			require.NoError(t, err)
+			require.Contains(t, content, tc.want)
+			require.NotContains(t, content, "data-url")
+		})
+	}
+}
+
+func Test_NewV2_WithSpecFetchOpts_SpecModifier(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("./data/loader")))
+	defer server.Close()
+
+	content, err := scalargo.NewV2(
+		scalargo.WithSpecURL(server.URL+"/pet-store.yml"),
+		scalargo.WithSpecFetchOpts(),
+		scalargo.WithSpecModifier(func(spec *model.Spec) *model.Spec {
+			spec.Info.Title = "PetStore API"
+			return spec
+		}),
+	)
+
+	require.NoError(t, err)
+	require.Contains(t, content, "<title>PetStore API</title>")
+}
+
+func Test_Renderer_WithSpecFetchOpts_Refresh(t *testing.T) {
+	var title atomic.Value
+	title.Store("v1")
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"openapi":"3.0.0","info":{"title":"` + title.Load().(string) + `"}}`))
+	}))
+	defer server.Close()
+
+	renderer, err := scalargo.NewRenderer(
+		scalargo.WithSpecURL(server.URL),
+		scalargo.WithSpecFetchOpts(scalargo.WithFetchRefreshInterval(20*time.Millisecond)),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	title.Store("v2")
+	content, err := renderer.Render(ctx)
+	require.NoError(t, err)
+	require.Contains(t, content, "<title>v1</title>", "spec must be cached until the refresh interval has passed")
+
+	require.Eventually(t, func() bool {
+		content, err = renderer.Render(ctx)
+		return err == nil && strings.Contains(content, "<title>v2</title>")
+	}, time.Second, 10*time.Millisecond)
+
+	failing.Store(true)
+	time.Sleep(30 * time.Millisecond)
+	content, err = renderer.Render(ctx)
+	require.NoError(t, err)
+	require.Contains(t, content, "<title>v2</title>", "last known-good spec must be kept when fetching fails")
+}
+
+func Test_Renderer_WithSpecFetchOpts_RefreshOutlivesTheRequest(t *testing.T) {
+	var title atomic.Value
+	title.Store("v1")
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write([]byte(`{"openapi":"3.0.0","info":{"title":"` + title.Load().(string) + `"}}`))
+	}))
+	defer server.Close()
+
+	renderer, err := scalargo.NewRenderer(
+		scalargo.WithSpecURL(server.URL),
+		scalargo.WithSpecFetchOpts(scalargo.WithFetchRefreshInterval(200*time.Millisecond)),
+	)
+	require.NoError(t, err)
+
+	title.Store("v2")
+	time.Sleep(200 * time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = renderer.Render(ctx)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return fetches.Load() == 2 }, time.Second, 5*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		content, err := renderer.Render(context.Background())
+		return err == nil && strings.Contains(content, "<title>v2</title>")
+	}, 100*time.Millisecond, 5*time.Millisecond, "refresh must not be canceled with the request that started it")
+	require.Equal(t, int32(2), fetches.Load(), "refresh must not run again before the interval has passed")
+}
+
+func Test_Handler_WithSpecFetchOpts(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("./data/loader")))
+	defer server.Close()
+
+	h, err := scalargo.Handler(
+		scalargo.WithSpecURL(server.URL+"/pet-store.yml"),
+		scalargo.WithSpecFetchOpts(),
+	)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"title":"Swagger Petstore"`)
+}
+
+// bearerTransport adds a bearer token to every request
+type bearerTransport string
+
+func (b bearerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+string(b))
+	return http.DefaultTransport.RoundTrip(r)
+}
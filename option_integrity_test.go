@@ -0,0 +1,83 @@
+package scalargo_test
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	stdhtml "html"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	scalargo "github.com/bdpiprava/scalar-go"
+	"github.com/bdpiprava/scalar-go/assets"
+)
+
+func Test_NewV2_ScalarVersion(t *testing.T) {
+	sum := sha512.Sum384(assets.ScalarBundle)
+	bundleIntegrity := "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+
+	testCases := []struct {
+		name      string
+		inputOpts []scalargo.Option
+		wantTag   string
+		wantError string
+	}{
+		{
+			name:      "should render unpinned CDN script without integrity by default",
+			inputOpts: []scalargo.Option{},
+			wantTag:   `<script src="https://cdn.jsdelivr.net/npm/@scalar/api-reference"></script>`,
+		},
+		{
+			name:      "should not use the hash of the embedded bundle for the CDN script",
+			inputOpts: []scalargo.Option{scalargo.WithScalarVersion(assets.ScalarVersion)},
+			wantError: "no known integrity hash for Scalar version " + assets.ScalarVersion + ", set it with WithIntegrity or use WithoutIntegrity",
+		},
+		{
+			name:      "should render self-hosted script with the hash of the embedded bundle",
+			inputOpts: []scalargo.Option{scalargo.WithSelfHosted("/docs/")},
+			wantTag: `<script src="/docs/` + assets.ScalarBundleName + `" integrity="` + bundleIntegrity +
+				`" crossorigin="anonymous"></script>`,
+		},
+		{
+			name:      "should fail when integrity of pinned version is unknown",
+			inputOpts: []scalargo.Option{scalargo.WithScalarVersion("0.0.1")},
+			wantError: "no known integrity hash for Scalar version 0.0.1, set it with WithIntegrity or use WithoutIntegrity",
+		},
+		{
+			name:      "should render pinned CDN script without integrity when opted out",
+			inputOpts: []scalargo.Option{scalargo.WithScalarVersion("0.0.1"), scalargo.WithoutIntegrity()},
+			wantTag:   `<script src="https://cdn.jsdelivr.net/npm/@scalar/api-reference@0.0.1/dist/browser/standalone.js"></script>`,
+		},
+		{
+			name:      "should render pinned CDN script with configured integrity",
+			inputOpts: []scalargo.Option{scalargo.WithScalarVersion("0.0.1"), scalargo.WithIntegrity("sha384-abc")},
+			wantTag: `<script src="https://cdn.jsdelivr.net/npm/@scalar/api-reference@0.0.1/dist/browser/standalone.js" ` +
+				`integrity="sha384-abc" crossorigin="anonymous"></script>`,
+		},
+		{
+			name:      "should render custom CDN script with configured integrity",
+			inputOpts: []scalargo.Option{scalargo.WithCDN("https://assets.example.com/scalar.js"), scalargo.WithIntegrity("sha384-abc")},
+			wantTag:   `<script src="https://assets.example.com/scalar.js" integrity="sha384-abc" crossorigin="anonymous"></script>`,
+		},
+		{
+			name:      "should fail when pinned version differs from self-hosted bundle",
+			inputOpts: []scalargo.Option{scalargo.WithSelfHosted(""), scalargo.WithScalarVersion("0.0.1")},
+			wantError: "self-hosted Scalar bundle is version " + assets.ScalarVersion + ", cannot pin version 0.0.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append([]scalargo.Option{scalargo.WithSpecURL(galaxySpecURL)}, tc.inputOpts...)
+			content, err := scalargo.NewV2(opts...)
+
+			if tc.wantError != "" {
+				require.EqualError(t, err, tc.wantError)
+				require.Empty(t, content)
+				return
+			}
+			require.NoError(t, err)
+			require.Contains(t, stdhtml.UnescapeString(content), tc.wantTag)
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package scalargo
+
+// renderOptions customize a single Render call
+type renderOptions struct {
+	nonce string
+}
+
+// RenderOption customizes a single Render call
+type RenderOption func(*renderOptions)
+
+// WithNonce adds the Content-Security-Policy nonce to every style and script tag of the page,
+// a fresh nonce must be generated for every response
+func WithNonce(nonce string) RenderOption {
+	return func(o *renderOptions) {
+		o.nonce = nonce
+	}
+}
@@ -0,0 +1,95 @@
+package scalargo
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+// RequestHook customizes the spec and the options for a request served by the Handler, e.g. to set the
+// servers from the Host header or to hide the operations the caller cannot call. It is called with copies
+// of the prepared spec and options, so the changes apply to this request only. The spec is nil when it is
+// loaded by the browser from SpecURL. With WithSources the hook is called for the spec of every source,
+// only the changes to the options made along with the first source are kept so they apply once. The page
+// template and assets cannot be changed per request.
+type RequestHook func(r *http.Request, spec *model.Spec, cfg *Options) error
+
+// WithRequestHook adds hooks the Handler runs in order on every request for the page or the spec,
+// an error fails the request with 500 Internal Server Error
+func WithRequestHook(hooks ...RequestHook) func(*Options) {
+	return func(o *Options) {
+		o.RequestHooks = append(o.RequestHooks, hooks...)
+	}
+}
+
+// forRequest returns a copy of the state with the specs and the configuration customized by the request
+// hooks, along with the customized options
+func (r *Renderer) forRequest(req *http.Request, state *renderState) (*renderState, *Options, error) {
+	options := r.options.clone()
+	custom := &renderState{documents: make([]*document, len(state.documents)), lastModified: state.lastModified}
+	for i, doc := range state.documents {
+		customized := *doc
+		custom.documents[i] = &customized
+
+		spec, err := doc.copySpec()
+		if err != nil {
+			return nil, nil, err
+		}
+		// the options are customized along with the first spec, the later specs get a copy to throw away
+		cfg := options
+		if i > 0 {
+			cfg = options.clone()
+		}
+		for _, hook := range options.RequestHooks {
+			if err := hook(req, spec, cfg); err != nil {
+				return nil, nil, fmt.Errorf("request hook: %w", err)
+			}
+		}
+		if spec == nil {
+			continue
+		}
+
+		customized.spec = spec
+		if customized.specJSON, err = json.Marshal(spec); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := custom.configure(options); err != nil {
+		return nil, nil, err
+	}
+	return custom, options, nil
+}
+
+// copySpec returns a deep copy of the loaded spec, nil when the spec was not loaded. The objects within
+// the paths, components and extensions are GenericObject like in the loaded spec.
+func (d *document) copySpec() (*model.Spec, error) {
+	if d.spec == nil {
+		return nil, nil
+	}
+
+	spec := &model.Spec{}
+	if err := json.Unmarshal(d.specJSON, spec); err != nil {
+		return nil, err
+	}
+	spec.KeyOrder = maps.Clone(d.spec.KeyOrder)
+	return spec, nil
+}
+
+// clone returns a copy of the options a request hook can change without affecting the original
+func (o *Options) clone() *Options {
+	c := *o
+	c.Configurations = maps.Clone(o.Configurations)
+	if metadata, ok := c.Configurations[keyMetaData].(MetaData); ok {
+		c.Configurations[keyMetaData] = maps.Clone(metadata)
+	}
+	c.Transformers = slices.Clip(o.Transformers)
+	c.LoaderOptions = slices.Clip(o.LoaderOptions)
+	c.Sources = slices.Clip(o.Sources)
+	c.RequestHooks = slices.Clip(o.RequestHooks)
+	return &c
+}
@@ -0,0 +1,173 @@
+package scalargo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	scalargo "github.com/bdpiprava/scalar-go"
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+// serversFromRequest sets the servers of the spec to the host the request was sent to
+func serversFromRequest(r *http.Request, spec *model.Spec, _ *scalargo.Options) error {
+	scheme := r.Header.Get("X-Forwarded-Proto")
+	if scheme == "" {
+		scheme = "http"
+	}
+	spec.Servers = []model.Server{{URL: scheme + "://" + r.Host + "/api"}}
+	return nil
+}
+
+// titleFromRequest sets the title of the page to the tenant the request is for
+func titleFromRequest(r *http.Request, _ *model.Spec, cfg *scalargo.Options) error {
+	cfg.Configurations["metadata"].(scalargo.MetaData)["title"] = "Docs for " + r.Header.Get("X-Tenant")
+	return nil
+}
+
+func Test_Handler_WithRequestHook(t *testing.T) {
+	h, err := scalargo.Handler(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+		scalargo.WithRequestHook(serversFromRequest, titleFromRequest),
+	)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	pages := make([]*httptest.ResponseRecorder, 10)
+	specs := make([]*httptest.ResponseRecorder, 10)
+	for i := range pages {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tenant := fmt.Sprintf("tenant-%d", i)
+
+			page := httptest.NewRequest(http.MethodGet, "/", nil)
+			page.Host = tenant + ".example.com"
+			page.Header.Set("X-Forwarded-Proto", "https")
+			page.Header.Set("X-Tenant", tenant)
+			pages[i] = httptest.NewRecorder()
+			h.ServeHTTP(pages[i], page)
+
+			spec := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+			spec.Host = tenant + ".example.com"
+			specs[i] = httptest.NewRecorder()
+			h.ServeHTTP(specs[i], spec)
+		}()
+	}
+	wg.Wait()
+
+	for i := range pages {
+		tenant := fmt.Sprintf("tenant-%d", i)
+
+		require.Equal(t, http.StatusOK, pages[i].Code)
+		content := parseContent(pages[i].Body.String())
+		require.Equal(t, "Docs for "+tenant, content.title)
+		require.Contains(t, content.spec, `"servers":[{"url":"https://`+tenant+`.example.com/api"}]`)
+		require.Contains(t, content.spec, `"paths":{"/pets":`)
+
+		require.Equal(t, http.StatusOK, specs[i].Code)
+		var got model.Spec
+		require.NoError(t, json.Unmarshal(specs[i].Body.Bytes(), &got))
+		require.Equal(t, []model.Server{{URL: "http://" + tenant + ".example.com/api"}}, got.Servers)
+	}
+}
+
+func Test_Handler_WithRequestHook_DoesNotChangePreparedSpec(t *testing.T) {
+	renderer, err := scalargo.NewRenderer(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+		scalargo.WithRequestHook(serversFromRequest, titleFromRequest),
+	)
+	require.NoError(t, err)
+	h, err := renderer.Handler()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Tenant", "acme")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	content, err := renderer.Render(req.Context())
+	require.NoError(t, err)
+	require.Equal(t, "Swagger Petstore", parseContent(content).title)
+	require.Contains(t, parseContent(content).spec, `"servers":[{"url":"http://petstore.swagger.io/v1"}]`)
+}
+
+func Test_Handler_WithRequestHook_GenericObjects(t *testing.T) {
+	summarize := func(_ *http.Request, spec *model.Spec, _ *scalargo.Options) error {
+		item, ok := spec.Paths["/pets"].(model.GenericObject)
+		if !ok {
+			return fmt.Errorf("path item is a %T", spec.Paths["/pets"])
+		}
+		operation, ok := item["get"].(model.GenericObject)
+		if !ok {
+			return fmt.Errorf("operation is a %T", item["get"])
+		}
+		operation["summary"] = "List the pets of the tenant"
+		return nil
+	}
+
+	h, err := scalargo.Handler(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+		scalargo.WithRequestHook(summarize),
+	)
+	require.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+	require.Contains(t, res.Body.String(), `"summary":"List the pets of the tenant"`)
+}
+
+func Test_Handler_WithRequestHook_Error(t *testing.T) {
+	h, err := scalargo.Handler(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+		scalargo.WithRequestHook(func(*http.Request, *model.Spec, *scalargo.Options) error {
+			return errors.New("unknown tenant")
+		}),
+	)
+	require.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	require.Equal(t, http.StatusInternalServerError, res.Code)
+	require.Equal(t, "request hook: unknown tenant\n", res.Body.String())
+}
+
+func Test_Handler_WithRequestHook_WithSources(t *testing.T) {
+	var specs []string
+	h, err := scalargo.Handler(
+		scalargo.WithSources(
+			scalargo.Source{
+				Title:   "Pet Store",
+				Options: []scalargo.Option{scalargo.WithSpecDir("./data/loader"), scalargo.WithBaseFileName("pet-store.yml")},
+			},
+			scalargo.Source{
+				Title:   "Pets",
+				Options: []scalargo.Option{scalargo.WithSpecDir("./data/loader"), scalargo.WithBaseFileName("pet-store.yml")},
+			},
+		),
+		scalargo.WithRequestHook(func(_ *http.Request, spec *model.Spec, cfg *scalargo.Options) error {
+			specs = append(specs, spec.Info.Title)
+			metadata := cfg.Configurations["metadata"].(scalargo.MetaData)
+			metadata["title"] = fmt.Sprint(metadata["title"]) + " for acme"
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+	require.Equal(t, []string{"Swagger Petstore", "Swagger Petstore"}, specs)
+	require.Equal(t, "API Reference for acme", parseContent(res.Body.String()).title)
+}
@@ -0,0 +1,71 @@
+package scalargo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Source is a named API document rendered in the document switcher of a multi-API portal
+type Source struct {
+	// Title is shown in the document switcher
+	Title string
+	// Slug identifies the document in the URL, derived from Title when empty
+	Slug string
+	// Default marks the document shown first, the first source is used when none is marked
+	Default bool
+	// Options configure where the spec of this source is loaded from and how it is modified,
+	// e.g. WithSpecDir, WithBaseFileName, WithSpecURL, WithSpecBytes and WithSpecModifier
+	Options []Option
+}
+
+// WithSources renders several API documents on one page using the Scalar sources configuration.
+// The spec of every source is loaded from its own options, the spec options of the page are ignored
+// except the transformers, e.g. WithAudience, which run on every source after its own.
+func WithSources(sources ...Source) func(*Options) {
+	return func(o *Options) {
+		o.Sources = append(o.Sources, sources...)
+	}
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify converts the title into a URL friendly slug
+func slugify(title string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(title), "-"), "-")
+}
+
+// validateSources checks the sources have unique slugs and at most one default,
+// it returns the slugs in the order of the sources and the index of the default source
+func validateSources(sources []Source) ([]string, int, error) {
+	slugs := make([]string, len(sources))
+	seen := map[string]string{}
+	defaultIndex := -1
+
+	for i, source := range sources {
+		slug := source.Slug
+		if slug == "" {
+			slug = slugify(source.Title)
+		}
+		if slug == "" {
+			return nil, 0, fmt.Errorf("source %d must have a title or a slug", i)
+		}
+		if title, ok := seen[slug]; ok {
+			return nil, 0, fmt.Errorf("sources '%s' and '%s' have the same slug '%s'", title, source.Title, slug)
+		}
+		seen[slug] = source.Title
+		slugs[i] = slug
+
+		if source.Default {
+			if defaultIndex >= 0 {
+				return nil, 0, fmt.Errorf("only one source can be the default, found '%s' and '%s'", sources[defaultIndex].Title, source.Title)
+			}
+			defaultIndex = i
+		}
+	}
+
+	if defaultIndex < 0 {
+		defaultIndex = 0
+	}
+	return slugs, defaultIndex, nil
+}
@@ -0,0 +1,156 @@
+package scalargo_test
+
+import (
+	"encoding/json"
+	stdhtml "html"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	scalargo "github.com/bdpiprava/scalar-go"
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+const galaxySpecURL = "https://cdn.jsdelivr.net/npm/@scalar/galaxy/dist/latest.yaml"
+
+var sourcesConfigurationMatcher = regexp.MustCompile(`<script id="api-reference" data-configuration="(.*)"></script>`)
+
+func petStoreSources() []scalargo.Source {
+	return []scalargo.Source{
+		{
+			Title:   "Pet Store",
+			Options: []scalargo.Option{scalargo.WithSpecDir("./data/loader"), scalargo.WithBaseFileName("pet-store.yml")},
+		},
+		{
+			Title:   "Galaxy",
+			Slug:    "galaxy-api",
+			Default: true,
+			Options: []scalargo.Option{scalargo.WithSpecURL(galaxySpecURL)},
+		},
+	}
+}
+
+func Test_NewV2_WithSources(t *testing.T) {
+	content, err := scalargo.NewV2(scalargo.WithSources(petStoreSources()...))
+	require.NoError(t, err)
+
+	matches := sourcesConfigurationMatcher.FindStringSubmatch(content)
+	require.Len(t, matches, 2)
+
+	var configuration struct {
+		Sources []struct {
+			Title   string      `json:"title"`
+			Slug    string      `json:"slug"`
+			Default bool        `json:"default"`
+			URL     string      `json:"url"`
+			Content *model.Spec `json:"content"`
+		} `json:"sources"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdhtml.UnescapeString(matches[1])), &configuration))
+
+	require.Len(t, configuration.Sources, 2)
+	petStore, galaxy := configuration.Sources[0], configuration.Sources[1]
+
+	require.Equal(t, "Pet Store", petStore.Title)
+	require.Equal(t, "pet-store", petStore.Slug)
+	require.False(t, petStore.Default)
+	require.Empty(t, petStore.URL)
+	require.Equal(t, "Swagger Petstore", petStore.Content.Info.Title)
+
+	require.Equal(t, "Galaxy", galaxy.Title)
+	require.Equal(t, "galaxy-api", galaxy.Slug)
+	require.True(t, galaxy.Default)
+	require.Equal(t, galaxySpecURL, galaxy.URL)
+	require.Nil(t, galaxy.Content)
+}
+
+func Test_NewV2_WithSources_InvalidSources(t *testing.T) {
+	testCases := []struct {
+		name      string
+		sources   []scalargo.Source
+		wantError string
+	}{
+		{
+			name: "should return error when slugs are not unique",
+			sources: []scalargo.Source{
+				{Title: "Pets API", Options: []scalargo.Option{scalargo.WithSpecURL(galaxySpecURL)}},
+				{Title: "Pets-API", Options: []scalargo.Option{scalargo.WithSpecURL(galaxySpecURL)}},
+			},
+			wantError: "sources 'Pets API' and 'Pets-API' have the same slug 'pets-api'",
+		},
+		{
+			name: "should return error when more than one source is the default",
+			sources: []scalargo.Source{
+				{Title: "One", Default: true, Options: []scalargo.Option{scalargo.WithSpecURL(galaxySpecURL)}},
+				{Title: "Two", Default: true, Options: []scalargo.Option{scalargo.WithSpecURL(galaxySpecURL)}},
+			},
+			wantError: "only one source can be the default, found 'One' and 'Two'",
+		},
+		{
+			name:      "should return error naming the source that failed to load",
+			sources:   []scalargo.Source{{Title: "Empty"}},
+			wantError: "source 'Empty': one of SpecURL, SpecDirectory or SpecBytes must be configured",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := scalargo.NewV2(scalargo.WithSources(tc.sources...))
+
+			require.EqualError(t, err, tc.wantError)
+			require.Empty(t, content)
+		})
+	}
+}
+
+func Test_NewV2_WithSources_PageTransformers(t *testing.T) {
+	content, err := scalargo.NewV2(
+		scalargo.WithSources(scalargo.Source{
+			Title: "Pet Store",
+			Options: []scalargo.Option{
+				scalargo.WithSpecDir("./data/loader"),
+				scalargo.WithBaseFileName("pet-store.yml"),
+				scalargo.WithTransformers(appendTitle(" (source)")),
+			},
+		}),
+		scalargo.WithTransformers(appendTitle(" (page)")),
+	)
+	require.NoError(t, err)
+
+	matches := sourcesConfigurationMatcher.FindStringSubmatch(content)
+	require.Len(t, matches, 2)
+
+	var configuration struct {
+		Sources []struct {
+			Content *model.Spec `json:"content"`
+		} `json:"sources"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdhtml.UnescapeString(matches[1])), &configuration))
+	require.Len(t, configuration.Sources, 1)
+	require.Equal(t, "Swagger Petstore (source) (page)", configuration.Sources[0].Content.Info.Title)
+}
+
+func Test_Handler_WithSources(t *testing.T) {
+	h, err := scalargo.Handler(scalargo.WithSources(petStoreSources()...))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pet-store/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var spec model.Spec
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &spec))
+	require.Equal(t, "Swagger Petstore", spec.Info.Title)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusFound, rec.Code)
+	require.Equal(t, galaxySpecURL, rec.Header().Get("Location"))
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/nope/openapi.json", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
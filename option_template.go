@@ -0,0 +1,142 @@
+package scalargo
+
+import (
+	"html/template"
+	"strings"
+)
+
+// pageTemplate is the default page layout. Custom templates given to WithTemplate can fill the
+// "head", "body-prefix" and "body-suffix" blocks, or redefine "layout" entirely while including
+// the "scalar-head" and "scalar-body" templates rendering the Scalar UI. The "scalar-fragment"
+// template renders the Scalar UI into the element with id MountID of an existing page.
+const pageTemplate = `{{define "layout"}}<!DOCTYPE html>
+<html>
+  <head>
+    <title>{{.Title}}</title>
+    <meta charset="utf-8" />
+    <meta name="viewport" content="width=device-width, initial-scale=1" />
+    {{template "scalar-head" .}}
+    {{- block "head" .}}{{end}}
+  </head>
+  <body>
+    {{- block "body-prefix" .}}{{end}}
+    {{- template "scalar-body" .}}
+    {{- block "body-suffix" .}}{{end}}
+  </body>
+</html>
+{{end}}
+
+{{- define "scalar-head"}}<style{{with .Nonce}} nonce="{{.}}"{{end}}>{{.OverrideCSS}}</style>{{end}}
+
+{{- define "scalar-body"}}{{template "scalar-spec" .}}
+    {{- if .HotReload}}
+    <script{{with .Nonce}} nonce="{{.}}"{{end}}>
+      (function () {
+        var events = new EventSource(location.pathname.replace(/\/?$/, "/") + {{.HotReloadPath}});
+        events.addEventListener("` + eventReload + `", function () { location.reload(); });
+        events.addEventListener("` + eventReloadError + `", function (e) { console.error("scalar-go: " + e.data); });
+      })();
+    </script>
+    {{- end}}
+    <script src="{{.ScriptSource}}"{{with .Integrity}} integrity="{{.}}" crossorigin="anonymous"{{end}}{{with .Nonce}} nonce="{{.}}"{{end}}></script>
+{{- end}}
+
+{{- define "scalar-spec"}}
+    {{- if .Spec}}
+    <script id="api-reference" type="application/json" data-configuration="{{.Configuration}}"{{with .Nonce}} nonce="{{.}}"{{end}}>{{.Spec}}</script>
+    {{- else if .SpecURL}}
+    <script id="api-reference" data-url="{{.SpecURL}}" data-configuration="{{.Configuration}}"{{with .Nonce}} nonce="{{.}}"{{end}}></script>
+    {{- else}}
+    <script id="api-reference" data-configuration="{{.Configuration}}"{{with .Nonce}} nonce="{{.}}"{{end}}></script>
+    {{- end}}
+{{- end}}
+
+{{- define "scalar-fragment"}}
+{{- with .OverrideCSS}}<style{{with $.Nonce}} nonce="{{.}}"{{end}}>{{.}}</style>
+{{end -}}
+<div id="{{.MountID}}"></div>
+<script{{with .Nonce}} nonce="{{.}}"{{end}}>
+  (function () {
+    var mount = function () {
+      Scalar.createApiReference(document.getElementById({{.MountID}}), {{.MountConfiguration}});
+    };
+    if (window.Scalar) {
+      mount();
+      return;
+    }
+    var bundle = document.querySelector("script[data-scalar-bundle]");
+    if (!bundle) {
+      bundle = document.createElement("script");
+      bundle.src = {{.ScriptSource}};
+      {{- with .Integrity}}
+      bundle.integrity = {{.}};
+      bundle.crossOrigin = "anonymous";
+      {{- end}}
+      {{- with .Nonce}}
+      bundle.nonce = {{.}};
+      {{- end}}
+      bundle.setAttribute("data-scalar-bundle", "");
+      document.head.appendChild(bundle);
+    }
+    bundle.addEventListener("load", mount);
+  })();
+</script>
+{{- end}}`
+
+// baseTemplate is the parsed default page layout
+var baseTemplate = template.Must(parsePageTemplate())
+
+// parsePageTemplate parses the default page layout, a template cannot be cloned once executed
+func parsePageTemplate() (*template.Template, error) {
+	return template.New("layout").Parse(pageTemplate)
+}
+
+// TemplateData is the data the page template is executed with
+type TemplateData struct {
+	// Title of the page
+	Title string
+	// OverrideCSS set with WithOverrideCSS
+	OverrideCSS template.CSS
+	// Nonce set with WithNonce, empty when there is none
+	Nonce string
+	// Configuration of the Scalar UI as JSON
+	Configuration string
+	// MountConfiguration of the Scalar UI as JSON including the spec, used by fragments
+	MountConfiguration template.JS
+	// MountID is the id of the element a fragment renders the Scalar UI into
+	MountID string
+	// SpecURL the Scalar UI loads the spec from when it is not inline
+	SpecURL string
+	// Spec is the inline spec as JSON, empty when the spec is loaded from SpecURL
+	Spec template.JS
+	// ScriptSource is the URL of the Scalar bundle
+	ScriptSource string
+	// Integrity is the Subresource Integrity hash of the Scalar bundle, empty when there is none
+	Integrity string
+	// HotReload is set when the page must refresh on reload events
+	HotReload bool
+	// HotReloadPath is the path of the reload events relative to the page
+	HotReloadPath string
+}
+
+// WithTemplate sets a custom page template. It can define the "head", "body-prefix" and "body-suffix"
+// blocks of the default layout, or redefine "layout" entirely including the "scalar-head" and
+// "scalar-body" templates. The template is executed with TemplateData.
+func WithTemplate(tmpl string) func(*Options) {
+	return func(o *Options) {
+		o.Template = tmpl
+	}
+}
+
+// pageTemplate returns the default page template extended with the custom template, if any
+func (o *Options) pageTemplate() (*template.Template, error) {
+	if strings.TrimSpace(o.Template) == "" {
+		return baseTemplate, nil
+	}
+
+	tmpl, err := parsePageTemplate()
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.Parse(o.Template)
+}
@@ -0,0 +1,75 @@
+package scalargo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	scalargo "github.com/bdpiprava/scalar-go"
+)
+
+func Test_NewV2_Escaping(t *testing.T) {
+	content, err := scalargo.NewV2(
+		scalargo.WithSpecURL(`https://example.com/spec.yaml?a="b"&c=<d>`),
+		scalargo.WithMetaDataOpts(scalargo.WithTitle(`</title><script>alert("x")</script>`)),
+	)
+	require.NoError(t, err)
+
+	require.NotContains(t, content, `<script>alert`)
+	require.Contains(t, content, `<title>&lt;/title&gt;&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;</title>`)
+	require.Contains(t, content, `data-url="https://example.com/spec.yaml?a=%22b%22&amp;c=%3cd%3e"`)
+}
+
+func Test_NewV2_WithTemplate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		template string
+		want     []string
+	}{
+		{
+			name:     "should fill the head block",
+			template: `{{define "head"}}<link rel="icon" href="/favicon.ico" />{{end}}`,
+			want:     []string{`<style></style><link rel="icon" href="/favicon.ico" />`},
+		},
+		{
+			name: "should fill the body blocks",
+			template: `{{define "body-prefix"}}<header>{{.Title}}</header>{{end}}` +
+				`{{define "body-suffix"}}<footer>Footer</footer>{{end}}`,
+			want: []string{
+				`<body><header>Swagger Petstore</header>`,
+				`</script><footer>Footer</footer>`,
+			},
+		},
+		{
+			name:     "should redefine the layout",
+			template: `{{define "layout"}}<main>{{template "scalar-head" .}}{{template "scalar-body" .}}</main>{{end}}`,
+			want: []string{
+				`<main><style></style>`,
+				`<script src="https://cdn.jsdelivr.net/npm/@scalar/api-reference"></script></main>`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := scalargo.NewV2(
+				scalargo.WithSpecDir("./data/loader"),
+				scalargo.WithBaseFileName("pet-store.yml"),
+				scalargo.WithTemplate(tc.template),
+			)
+			require.NoError(t, err)
+
+			for _, want := range tc.want {
+				require.Contains(t, content, want)
+			}
+		})
+	}
+}
+
+func Test_NewRenderer_WithInvalidTemplate(t *testing.T) {
+	_, err := scalargo.NewRenderer(
+		scalargo.WithSpecURL(galaxySpecURL),
+		scalargo.WithTemplate(`{{define "head"}}{{.Missing`),
+	)
+	require.ErrorContains(t, err, "unclosed action")
+}
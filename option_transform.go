@@ -0,0 +1,73 @@
+package scalargo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+// Transformer changes the spec after it is loaded from any source and before it is rendered
+type Transformer interface {
+	// Name identifies the transformer in the errors it returns
+	Name() string
+	// Transform changes the spec in place, an error stops the rendering
+	Transform(ctx context.Context, spec *model.Spec) error
+}
+
+// transformerFunc is a Transformer made of a name and a function
+type transformerFunc struct {
+	name string
+	fn   func(ctx context.Context, spec *model.Spec) error
+}
+
+// NewTransformer returns a Transformer calling the function
+func NewTransformer(name string, fn func(ctx context.Context, spec *model.Spec) error) Transformer {
+	return transformerFunc{name: name, fn: fn}
+}
+
+// Name returns the name of the transformer
+func (t transformerFunc) Name() string {
+	return t.name
+}
+
+// Transform calls the function of the transformer
+func (t transformerFunc) Transform(ctx context.Context, spec *model.Spec) error {
+	return t.fn(ctx, spec)
+}
+
+// modifierTransformer adapts a SpecModifier to a Transformer
+func modifierTransformer(modifier SpecModifier) Transformer {
+	return NewTransformer("spec modifier", func(_ context.Context, spec *model.Spec) error {
+		if modified := modifier(spec); modified != nil && modified != spec {
+			*spec = *modified
+		}
+		return nil
+	})
+}
+
+// WithTransformers adds transformers run in order on the loaded spec, after the ones added before.
+// It can be used many times and applies to every source, after the transformers of the source, see Transformer.
+func WithTransformers(transformers ...Transformer) func(*Options) {
+	return func(o *Options) {
+		o.Transformers = append(o.Transformers, transformers...)
+	}
+}
+
+// transformers returns the deprecated SpecModifier as a Transformer followed by the transformers
+func (o *Options) transformers() []Transformer {
+	if o.SpecModifier == nil {
+		return o.Transformers
+	}
+	return append([]Transformer{modifierTransformer(o.SpecModifier)}, o.Transformers...)
+}
+
+// transform runs the deprecated SpecModifier and then the transformers on the spec
+func (o *Options) transform(ctx context.Context, spec *model.Spec) error {
+	for _, transformer := range o.transformers() {
+		if err := transformer.Transform(ctx, spec); err != nil {
+			return fmt.Errorf("transformer '%s': %w", transformer.Name(), err)
+		}
+	}
+	return nil
+}
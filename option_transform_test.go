@@ -0,0 +1,104 @@
+package scalargo_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	scalargo "github.com/bdpiprava/scalar-go"
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+// appendTitle returns a transformer appending the suffix to the title of the spec
+func appendTitle(suffix string) scalargo.Transformer {
+	return scalargo.NewTransformer("append "+suffix, func(_ context.Context, spec *model.Spec) error {
+		spec.Info.Title += suffix
+		return nil
+	})
+}
+
+func Test_NewV2_WithTransformers(t *testing.T) {
+	petStore, err := os.ReadFile("./data/loader/pet-store.yml")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write(petStore) }))
+	defer server.Close()
+
+	transformers := []scalargo.Option{
+		scalargo.WithSpecModifier(func(spec *model.Spec) *model.Spec {
+			spec.Info.Title += " 1"
+			return spec
+		}),
+		scalargo.WithTransformers(appendTitle(" 2"), appendTitle(" 3")),
+		scalargo.WithSpecModifier(func(spec *model.Spec) *model.Spec {
+			spec.Info.Title += " 4"
+			return spec
+		}),
+	}
+
+	testCases := []struct {
+		name string
+		opts []scalargo.Option
+	}{
+		{name: "dir", opts: []scalargo.Option{scalargo.WithSpecDir("./data/loader"), scalargo.WithBaseFileName("pet-store.yml")}},
+		{name: "bytes", opts: []scalargo.Option{scalargo.WithSpecBytes(petStore)}},
+		{name: "fs", opts: []scalargo.Option{
+			scalargo.WithSpecFS(fstest.MapFS{"api/api.yaml": {Data: petStore}}, "api"),
+		}},
+		{name: "fetched url", opts: []scalargo.Option{scalargo.WithSpecURL(server.URL), scalargo.WithSpecFetchOpts()}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := scalargo.NewV2(append(tc.opts, transformers...)...)
+
+			require.NoError(t, err)
+			require.Equal(t, "Swagger Petstore 1 2 3 4", parseContent(content).title)
+		})
+	}
+}
+
+func Test_NewV2_WithTransformers_Error(t *testing.T) {
+	var called bool
+	content, err := scalargo.NewV2(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+		scalargo.WithTransformers(
+			scalargo.NewTransformer("require version", func(ctx context.Context, spec *model.Spec) error {
+				require.NotNil(t, ctx)
+				return errors.New("no version in '" + spec.Info.Title + "'")
+			}),
+			scalargo.NewTransformer("never", func(context.Context, *model.Spec) error {
+				called = true
+				return nil
+			}),
+		),
+	)
+
+	require.Empty(t, content)
+	require.EqualError(t, err, "transformer 'require version': no version in 'Swagger Petstore'")
+	require.False(t, called)
+}
+
+func Test_NewV2_WithDeprecatedSpecModifierField(t *testing.T) {
+	content, err := scalargo.NewV2(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+		scalargo.WithTransformers(appendTitle(" 2")),
+		func(o *scalargo.Options) {
+			o.SpecModifier = func(spec *model.Spec) *model.Spec {
+				spec.Info.Title += " 1"
+				return spec
+			}
+		},
+	)
+
+	require.NoError(t, err)
+	require.Equal(t, "Swagger Petstore 1 2", parseContent(content).title)
+}
@@ -3,14 +3,20 @@ package scalargo
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"strings"
+	"time"
 
+	"github.com/bdpiprava/scalar-go/loader"
 	"github.com/bdpiprava/scalar-go/model"
 )
 
 // DefaultCDN default CDN for api-reference
 const DefaultCDN = "https://cdn.jsdelivr.net/npm/@scalar/api-reference"
 
+// defaultCacheControl makes browsers revalidate the docs using ETag and Last-Modified on every visit
+const defaultCacheControl = "no-cache"
+
 const (
 	keyTheme              = "theme"
 	keyLayout             = "layout"
@@ -30,9 +36,12 @@ const (
 	keyWithDefaultFonts   = "withDefaultFonts"
 	keyServers            = "servers"
 	keyMetaData           = "metadata"
+	keySources            = "sources"
+	keyURL                = "url"
+	keyContent            = "content"
 )
 
-// SpecModifier is a function that can be used to override the spec
+// SpecModifier is a function that can be used to override the spec, see WithSpecModifier
 type SpecModifier func(spec *model.Spec) *model.Spec
 
 type Options struct {
@@ -40,10 +49,28 @@ type Options struct {
 	OverrideCSS    string
 	BaseFileName   string
 	CDN            string
-	SpecModifier   SpecModifier
-	SpecDirectory  string
-	SpecURL        string
-	SpecBytes      []byte
+	Template       string
+	ScalarVersion  string
+	Integrity      string
+	SkipIntegrity  bool
+	SelfHosted     bool
+	AssetsBasePath string
+	CacheControl   string
+	// Deprecated: SpecModifier holds a single modifier run before the Transformers, use WithSpecModifier
+	// or WithTransformers to add to the Transformers instead
+	SpecModifier  SpecModifier
+	Transformers  []Transformer
+	RequestHooks  []RequestHook
+	SpecDirectory string
+	SpecFS        fs.FS
+	SpecURL       string
+	Fetch         *FetchOptions
+	SpecBytes     []byte
+	LoaderOptions []loader.Option
+	Sources       []Source
+
+	HotReloadInterval     time.Duration
+	ContentSecurityPolicy bool
 }
 
 type Option func(*Options)
@@ -55,6 +82,23 @@ func WithCDN(cdn string) func(*Options) {
 	}
 }
 
+// WithSelfHosted serves the Scalar bundle embedded in the module from the Handler instead of the CDN.
+// The basePath is the URL path the Handler is mounted under, e.g. "/docs/", when empty the bundle
+// is referenced relative to the page. A CDN set with WithCDN still takes precedence.
+func WithSelfHosted(basePath string) func(*Options) {
+	return func(o *Options) {
+		o.SelfHosted = true
+		o.AssetsBasePath = basePath
+	}
+}
+
+// WithCacheControl sets the Cache-Control header value used by the Handler
+func WithCacheControl(cacheControl string) func(*Options) {
+	return func(o *Options) {
+		o.CacheControl = cacheControl
+	}
+}
+
 // WithProxy sets the proxy for the Scalar UI
 func WithProxy(proxy string) func(*Options) {
 	return func(o *Options) {
@@ -179,10 +223,11 @@ func WithBaseFileName(baseFileName string) func(*Options) {
 	}
 }
 
-// WithSpecModifier allows to modify the spec before rendering
+// WithSpecModifier allows to modify the spec before rendering, it adds the handler to the
+// Transformers so it can be used many times, see WithTransformers
 func WithSpecModifier(handler SpecModifier) func(*Options) {
 	return func(o *Options) {
-		o.SpecModifier = handler
+		o.Transformers = append(o.Transformers, modifierTransformer(handler))
 	}
 }
 
@@ -190,6 +235,24 @@ func WithSpecModifier(handler SpecModifier) func(*Options) {
 func WithSpecDir(specDir string) func(*Options) {
 	return func(o *Options) {
 		o.SpecDirectory = specDir
+		o.SpecFS = nil
+	}
+}
+
+// WithSpecFS sets the directory within fsys to load the spec from, e.g. a directory tree embedded
+// with //go:embed. It is loaded like WithSpecDir, the base file is set with WithBaseFileName.
+func WithSpecFS(fsys fs.FS, root string) func(*Options) {
+	return func(o *Options) {
+		o.SpecDirectory = root
+		o.SpecFS = fsys
+	}
+}
+
+// WithHotReload polls the spec directory, and the files referenced from outside it, for changes at the given interval, reloads the spec
+// and refreshes the open pages served by the Handler, meant for development only
+func WithHotReload(interval time.Duration) func(*Options) {
+	return func(o *Options) {
+		o.HotReloadInterval = interval
 	}
 }
 
@@ -207,6 +270,14 @@ func WithSpecBytes(specBytes []byte) func(*Options) {
 	}
 }
 
+// WithLoaderOptions sets the options used to load the spec from SpecDirectory,
+// e.g. WithLoaderOptions(loader.WithRefMode(loader.BundleRefs))
+func WithLoaderOptions(opts ...loader.Option) func(*Options) {
+	return func(o *Options) {
+		o.LoaderOptions = append(o.LoaderOptions, opts...)
+	}
+}
+
 // WithAuthenticationOpts sets the authentication method for the Scalar UI
 func WithAuthenticationOpts(opts ...AuthOption) func(*Options) {
 	auth := make(AuthenticationOption)
@@ -0,0 +1,211 @@
+package scalargo
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HotReloadEventsPath is the sub-path the Handler streams reload events from when hot reload is enabled
+const HotReloadEventsPath = "scalar-reload"
+
+const (
+	eventReload      = "reload"
+	eventReloadError = "reload-error"
+)
+
+// event is a Server-Sent Event pushed to the open pages
+type event struct {
+	name string
+	data string
+}
+
+// fileStamp identifies the version of a file by its modification time and size
+type fileStamp struct {
+	modTime int64
+	size    int64
+}
+
+// reloader polls the spec directories and the files read by the last load, swaps the prepared specs of the Renderer
+// when a file changes and notifies the subscribed pages
+type reloader struct {
+	renderer    *Renderer
+	interval    time.Duration
+	dirs        []string
+	stop        chan struct{}
+	done        chan struct{}
+	mu          sync.Mutex
+	subscribers map[chan event]struct{}
+	closed      bool
+}
+
+// newReloader creates a reloader and starts polling the spec directories
+func newReloader(renderer *Renderer, interval time.Duration, dirs []string) *reloader {
+	rl := &reloader{
+		renderer:    renderer,
+		interval:    interval,
+		dirs:        dirs,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+		subscribers: map[chan event]struct{}{},
+	}
+
+	go rl.run(rl.snapshot())
+	return rl
+}
+
+// run polls the spec files until the reloader is closed
+func (rl *reloader) run(snapshot map[string]fileStamp) {
+	defer close(rl.done)
+
+	ticker := time.NewTicker(rl.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rl.stop:
+			return
+		case <-ticker.C:
+			current := rl.snapshot()
+			if maps.Equal(snapshot, current) {
+				continue
+			}
+			snapshot = current
+
+			state, err := rl.renderer.prepare(context.Background())
+			if err != nil {
+				rl.broadcast(event{name: eventReloadError, data: err.Error()})
+				continue
+			}
+			rl.renderer.state.Store(state)
+			// the new specs may reference files the previous ones did not
+			snapshot = rl.snapshot()
+			rl.broadcast(event{name: eventReload, data: state.lastModified.Format(time.RFC3339Nano)})
+		}
+	}
+}
+
+// snapshot returns the stamp of every file under the spec directories and of every file read by the last load,
+// files referenced from outside the directories are watched as well
+func (rl *reloader) snapshot() map[string]fileStamp {
+	snapshot := snapshotDirs(rl.dirs)
+	for _, name := range rl.renderer.watchedFiles() {
+		if _, ok := snapshot[name]; ok {
+			continue
+		}
+		if info, err := os.Stat(name); err == nil {
+			snapshot[name] = fileStamp{modTime: info.ModTime().UnixNano(), size: info.Size()}
+		}
+	}
+	return snapshot
+}
+
+// snapshotDirs returns the stamp of every file under the dirs, unreadable entries are skipped
+func snapshotDirs(dirs []string) map[string]fileStamp {
+	snapshot := map[string]fileStamp{}
+	for _, dir := range dirs {
+		_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return nil
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			snapshot[path] = fileStamp{modTime: info.ModTime().UnixNano(), size: info.Size()}
+			return nil
+		})
+	}
+	return snapshot
+}
+
+// subscribe registers a new subscriber, the returned function must be called to unsubscribe
+func (rl *reloader) subscribe() (<-chan event, func()) {
+	events := make(chan event, 1)
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.closed {
+		close(events)
+		return events, func() {}
+	}
+	rl.subscribers[events] = struct{}{}
+
+	return events, func() {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		if _, ok := rl.subscribers[events]; ok {
+			delete(rl.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+// broadcast sends the event to every subscriber without blocking on slow ones
+func (rl *reloader) broadcast(e event) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	for events := range rl.subscribers {
+		select {
+		case events <- e:
+		default:
+		}
+	}
+}
+
+// close stops polling and disconnects all subscribers
+func (rl *reloader) close() {
+	rl.mu.Lock()
+	if rl.closed {
+		rl.mu.Unlock()
+		return
+	}
+	rl.closed = true
+	for events := range rl.subscribers {
+		delete(rl.subscribers, events)
+		close(events)
+	}
+	rl.mu.Unlock()
+
+	close(rl.stop)
+	<-rl.done
+}
+
+// ServeHTTP streams the reload events to the page as Server-Sent Events
+func (rl *reloader) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe := rl.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.name, strings.ReplaceAll(e.data, "\n", "\ndata: "))
+			flusher.Flush()
+		}
+	}
+}
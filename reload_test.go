@@ -0,0 +1,124 @@
+package scalargo_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	scalargo "github.com/bdpiprava/scalar-go"
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+func Test_Handler_HotReload(t *testing.T) {
+	dir := t.TempDir()
+	specFile := filepath.Join(dir, "api.yaml")
+	require.NoError(t, os.WriteFile(specFile, []byte("openapi: 3.0.0\ninfo:\n  title: Before\n"), 0o600))
+
+	h, err := scalargo.Handler(scalargo.WithSpecDir(dir), scalargo.WithHotReload(10*time.Millisecond))
+	require.NoError(t, err)
+	defer h.(io.Closer).Close()
+
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+
+	page := get(t, server.URL+"/")
+	require.Contains(t, page, `"scalar-reload"`)
+
+	events := subscribe(t, server.URL+"/scalar-reload")
+
+	require.NoError(t, os.WriteFile(specFile, []byte("openapi: 3.0.0\ninfo:\n  title: After the change\n"), 0o600))
+	require.Equal(t, "event: reload\n", nextEvent(t, events))
+
+	var spec model.Spec
+	require.NoError(t, json.Unmarshal([]byte(get(t, server.URL+"/openapi.json")), &spec))
+	require.Equal(t, "After the change", spec.Info.Title)
+	require.Equal(t, "After the change", parseContent(get(t, server.URL+"/")).title)
+}
+
+func Test_Handler_HotReload_ShouldWatchReferencedFilesOutsideTheDirectory(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "api")
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	commonFile := filepath.Join(root, "common.yaml")
+	require.NoError(t, os.WriteFile(commonFile, []byte("get:\n  summary: Before\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "api.yaml"), []byte("openapi: 3.0.0\ninfo:\n  title: Pets\npaths:\n  /pets:\n    $ref: ../common.yaml\n"), 0o600))
+
+	h, err := scalargo.Handler(scalargo.WithSpecDir(dir), scalargo.WithHotReload(10*time.Millisecond))
+	require.NoError(t, err)
+	defer h.(io.Closer).Close()
+
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+
+	events := subscribe(t, server.URL+"/scalar-reload")
+
+	require.NoError(t, os.WriteFile(commonFile, []byte("get:\n  summary: After the change\n"), 0o600))
+	require.Equal(t, "event: reload\n", nextEvent(t, events))
+
+	require.Contains(t, get(t, server.URL+"/openapi.json"), `"summary":"After the change"`)
+}
+
+func Test_Renderer_WithoutHotReload_ShouldNotInjectScript(t *testing.T) {
+	renderer, err := scalargo.NewRenderer(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+	)
+	require.NoError(t, err)
+
+	content, err := renderer.Render(context.Background())
+	require.NoError(t, err)
+	require.NotContains(t, content, "scalar-reload")
+}
+
+func get(t *testing.T, url string) string {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	return string(body)
+}
+
+// subscribe opens the reload event stream and waits for the connection to be acknowledged
+func subscribe(t *testing.T, url string) *bufio.Reader {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = res.Body.Close() })
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	events := bufio.NewReader(res.Body)
+	line, err := events.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, ": connected\n", line)
+	return events
+}
+
+// nextEvent returns the event line of the next event of the stream
+func nextEvent(t *testing.T, events *bufio.Reader) string {
+	for {
+		line, err := events.ReadString('\n')
+		require.NoError(t, err)
+		if strings.HasPrefix(line, "event: ") {
+			return line
+		}
+	}
+}
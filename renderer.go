@@ -0,0 +1,356 @@
+package scalargo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bdpiprava/scalar-go/loader"
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+// Renderer renders the Scalar UI from options and a spec prepared once.
+// It is safe for concurrent use by multiple goroutines.
+type Renderer struct {
+	options   *Options
+	template  *template.Template
+	documents []*document
+	state     atomic.Pointer[renderState]
+	reload    *reloader
+
+	// refreshInterval is how often the specs fetched on the server are fetched again, zero for never
+	refreshInterval time.Duration
+	// refreshedAt is the time in Unix nanoseconds the last fetch of the specs completed, successfully or not
+	refreshedAt atomic.Int64
+	refreshing  sync.Mutex
+}
+
+// renderState holds everything prepared from the options and the loaded specs,
+// it is replaced as a whole when the specs are reloaded
+type renderState struct {
+	documents    []*document
+	title        string
+	configJSON   string
+	mountJSON    string
+	lastModified time.Time
+}
+
+// document is an API spec prepared for rendering, a multi-API portal has one per source
+type document struct {
+	title     string
+	slug      string
+	isDefault bool
+	options   *Options
+	spec      *model.Spec
+	specJSON  json.RawMessage
+	files     loader.Files
+}
+
+// sourceConfiguration is the Scalar configuration of a document in a multi-API portal
+type sourceConfiguration struct {
+	Title   string          `json:"title"`
+	Slug    string          `json:"slug"`
+	Default bool            `json:"default,omitempty"`
+	URL     string          `json:"url,omitempty"`
+	Content json.RawMessage `json:"content,omitempty"`
+}
+
+// NewRenderer builds the options, loads the spec and prepares everything needed to render the Scalar UI.
+// When hot reload is enabled the Renderer watches the spec directory until Close is called.
+func NewRenderer(opts ...Option) (*Renderer, error) {
+	options := buildOptions(opts...)
+	if _, _, err := options.scriptSource(); err != nil {
+		return nil, err
+	}
+
+	tmpl, err := options.pageTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := newDocuments(options)
+	if err != nil {
+		return nil, err
+	}
+
+	renderer := &Renderer{options: options, template: tmpl, documents: documents}
+	state, err := renderer.prepare(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	renderer.state.Store(state)
+	renderer.refreshedAt.Store(time.Now().UnixNano())
+
+	for _, doc := range documents {
+		if interval := doc.refreshInterval(); interval > 0 && (renderer.refreshInterval == 0 || interval < renderer.refreshInterval) {
+			renderer.refreshInterval = interval
+		}
+	}
+
+	if dirs := renderer.watchedDirs(); options.HotReloadInterval > 0 && len(dirs) > 0 {
+		renderer.reload = newReloader(renderer, options.HotReloadInterval, dirs)
+	}
+	return renderer, nil
+}
+
+// newDocuments returns the documents to render, a single one from the options unless sources are configured
+func newDocuments(options *Options) ([]*document, error) {
+	if len(options.Sources) == 0 {
+		return []*document{{isDefault: true, options: options}}, nil
+	}
+
+	slugs, defaultIndex, err := validateSources(options.Sources)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]*document, len(options.Sources))
+	for i, source := range options.Sources {
+		// the transformers of the page run on every source after its own
+		sourceOptions := buildOptions(source.Options...)
+		sourceOptions.Transformers = append(slices.Clip(sourceOptions.Transformers), options.transformers()...)
+
+		documents[i] = &document{
+			title:     source.Title,
+			slug:      slugs[i],
+			isDefault: i == defaultIndex,
+			options:   sourceOptions,
+		}
+	}
+	return documents, nil
+}
+
+// prepare loads the specs and renders the spec script from the options
+func (r *Renderer) prepare(ctx context.Context) (*renderState, error) {
+	state := &renderState{documents: make([]*document, len(r.documents))}
+	for i, doc := range r.documents {
+		loaded, err := doc.load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		state.documents[i] = loaded
+
+		if lastModified := loaded.files.LastModified(); lastModified.After(state.lastModified) {
+			state.lastModified = lastModified
+		}
+	}
+	if state.lastModified.IsZero() {
+		state.lastModified = time.Now()
+	}
+
+	if err := state.configure(r.options); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// configure renders the configuration and the title of the state from the options and the loaded documents
+func (s *renderState) configure(options *Options) error {
+	var configuration map[string]any
+	if len(options.Sources) == 0 {
+		configuration = options.configurationFor(s.documents[0].spec)
+	} else {
+		configuration = options.configurationFor(nil)
+		configuration[keySources] = sourceConfigurations(s.documents)
+	}
+
+	configJSON, err := configurationJSON(configuration)
+	if err != nil {
+		return err
+	}
+	s.configJSON = configJSON
+
+	if len(options.Sources) == 0 {
+		doc := s.documents[0]
+		if doc.remoteURL() != "" {
+			configuration[keyURL] = doc.remoteURL()
+		} else {
+			configuration[keyContent] = doc.specJSON
+		}
+	}
+	if s.mountJSON, err = configurationJSON(configuration); err != nil {
+		return err
+	}
+
+	s.title = fmt.Sprintf("%v", configuration[keyMetaData].(MetaData)["title"])
+	return nil
+}
+
+// watchedDirs returns the spec directories of the documents loaded from a directory of the OS filesystem
+func (r *Renderer) watchedDirs() []string {
+	var dirs []string
+	for _, doc := range r.documents {
+		if doc.specURL() == "" && doc.options.SpecFS == nil && doc.options.SpecDirectory != "" {
+			dirs = append(dirs, doc.options.SpecDirectory)
+		}
+	}
+	return dirs
+}
+
+// watchedFiles returns the files read by the last load of the documents loaded from the OS filesystem
+func (r *Renderer) watchedFiles() []string {
+	var files []string
+	for _, doc := range r.state.Load().documents {
+		if doc.options.SpecFS == nil {
+			files = slices.AppendSeq(files, maps.Keys(doc.files))
+		}
+	}
+	return files
+}
+
+// load returns a copy of the document with its spec loaded, the spec is not loaded when
+// referenced by URL unless it is fetched on the server
+func (d *document) load(ctx context.Context) (*document, error) {
+	loaded := *d
+	if d.remoteURL() != "" {
+		return &loaded, nil
+	}
+
+	spec, files, err := d.options.loadSpec(ctx)
+	if err != nil {
+		if d.title != "" {
+			return nil, fmt.Errorf("source '%s': %w", d.title, err)
+		}
+		return nil, err
+	}
+
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded.spec = spec
+	loaded.specJSON = specJSON
+	loaded.files = files
+	return &loaded, nil
+}
+
+// specURL returns the configured SpecURL when the spec is referenced by URL
+func (d *document) specURL() string {
+	return strings.TrimSpace(d.options.SpecURL)
+}
+
+// remoteURL returns the SpecURL when the spec is loaded by the browser rather than on the server
+func (d *document) remoteURL() string {
+	if d.options.fetchesSpec() {
+		return ""
+	}
+	return d.specURL()
+}
+
+// refreshInterval returns how often the spec fetched on the server is fetched again, zero for never
+func (d *document) refreshInterval() time.Duration {
+	if !d.options.fetchesSpec() {
+		return 0
+	}
+	return d.options.Fetch.RefreshInterval
+}
+
+// document returns the document with the given slug, or the default document when the slug is empty,
+// nil when no document has the slug
+func (s *renderState) document(slug string) *document {
+	for _, doc := range s.documents {
+		if (slug == "" && doc.isDefault) || (slug != "" && doc.slug == slug) {
+			return doc
+		}
+	}
+	return nil
+}
+
+// LastModified returns the latest modification time of the spec files,
+// or the time the spec was prepared when it was not loaded from files
+func (r *Renderer) LastModified() time.Time {
+	return r.state.Load().lastModified
+}
+
+// refresh fetches the specs fetched on the server again in the background once the refresh interval has passed,
+// the current specs are served meanwhile. The fetch is detached from the cancellation of ctx, so a client going
+// away does not cancel it. Only one refresh runs at a time and the last known-good specs are kept when it fails.
+func (r *Renderer) refresh(ctx context.Context) {
+	if r.refreshInterval <= 0 || !r.refreshDue() || !r.refreshing.TryLock() {
+		return
+	}
+	if !r.refreshDue() {
+		r.refreshing.Unlock()
+		return
+	}
+
+	go func() {
+		defer r.refreshing.Unlock()
+		if state, err := r.prepare(context.WithoutCancel(ctx)); err == nil {
+			r.state.Store(state)
+		}
+		r.refreshedAt.Store(time.Now().UnixNano())
+	}()
+}
+
+// refreshDue reports whether the refresh interval has passed since the specs were last fetched
+func (r *Renderer) refreshDue() bool {
+	return time.Since(time.Unix(0, r.refreshedAt.Load())) >= r.refreshInterval
+}
+
+// Render generates the HTML for the Scalar UI
+func (r *Renderer) Render(ctx context.Context, opts ...RenderOption) (string, error) {
+	r.refresh(ctx)
+	return r.render(r.state.Load(), r.options, opts...)
+}
+
+// render generates the HTML for the Scalar UI from the state and the options
+func (r *Renderer) render(state *renderState, options *Options, opts ...RenderOption) (string, error) {
+	data, err := r.templateData(state, options, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	var page strings.Builder
+	if err := r.template.ExecuteTemplate(&page, "layout", data); err != nil {
+		return "", err
+	}
+	return page.String(), nil
+}
+
+// templateData returns the data the templates are executed with
+func (r *Renderer) templateData(state *renderState, options *Options, opts ...RenderOption) (*TemplateData, error) {
+	renderOpts := &renderOptions{}
+	for _, opt := range opts {
+		opt(renderOpts)
+	}
+
+	src, integrity, err := options.scriptSource()
+	if err != nil {
+		return nil, err
+	}
+
+	data := &TemplateData{
+		Title:              state.title,
+		OverrideCSS:        template.CSS(options.OverrideCSS), // #nosec G203 -- configured by the application
+		Nonce:              renderOpts.nonce,
+		Configuration:      state.configJSON,
+		MountConfiguration: template.JS(state.mountJSON), // #nosec G203 -- json.Marshal escapes <, > and &
+		ScriptSource:       src,
+		Integrity:          integrity,
+		HotReload:          r.reload != nil,
+		HotReloadPath:      HotReloadEventsPath,
+	}
+	if len(options.Sources) == 0 {
+		doc := state.documents[0]
+		data.SpecURL = doc.remoteURL()
+		data.Spec = template.JS(doc.specJSON) // #nosec G203 -- json.Marshal escapes <, > and &
+	}
+	return data, nil
+}
+
+// Close stops watching the spec directory when hot reload is enabled
+func (r *Renderer) Close() error {
+	if r.reload != nil {
+		r.reload.close()
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package scalargo_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	scalargo "github.com/bdpiprava/scalar-go"
+	"github.com/bdpiprava/scalar-go/model"
+)
+
+func Test_Renderer_ShouldLoadSpecOnce(t *testing.T) {
+	var calls int
+	renderer, err := scalargo.NewRenderer(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+		scalargo.WithSpecModifier(func(spec *model.Spec) *model.Spec {
+			calls++
+			return spec
+		}),
+	)
+	require.NoError(t, err)
+
+	for range 3 {
+		content, err := renderer.Render(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "Swagger Petstore", parseContent(content).title)
+	}
+	require.Equal(t, 1, calls)
+}
+
+func Test_Renderer_ShouldBeSafeForConcurrentRender(t *testing.T) {
+	renderer, err := scalargo.NewRenderer(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+	)
+	require.NoError(t, err)
+
+	expected, err := renderer.Render(context.Background())
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = renderer.Render(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	for _, got := range results {
+		require.Equal(t, expected, got)
+	}
+}
+
+func Test_GetSpecScript_ShouldNotMutateOptions(t *testing.T) {
+	options := &scalargo.Options{
+		Configurations: map[string]any{"metadata": scalargo.MetaData{"title": "API Reference"}},
+		SpecBytes:      []byte(`{"openapi":"3.0.0","info":{"title":"Swagger Petstore"}}`),
+	}
+
+	script, err := options.GetSpecScript()
+
+	require.NoError(t, err)
+	require.Contains(t, script, `&#34;metadata&#34;:{&#34;title&#34;:&#34;Swagger Petstore&#34;}`)
+	require.Equal(t, scalargo.MetaData{"title": "API Reference"}, options.Configurations["metadata"])
+}
@@ -11,9 +11,32 @@ func Sanitize(spec *model.Spec) *model.Spec {
 	spec.Components.Schemas = sanitizeGenericObject(spec.Components.Schemas)
 	spec.Components.Parameters = sanitizeGenericObject(spec.Components.Parameters)
 	spec.Paths = sanitizeGenericObject(spec.Paths)
+
+	for _, section := range []*model.GenericObject{
+		&spec.Webhooks, &spec.Extensions, &spec.Info.Extensions,
+		&spec.Components.Responses, &spec.Components.Examples, &spec.Components.RequestBodies,
+		&spec.Components.Headers, &spec.Components.SecuritySchemes, &spec.Components.Link,
+		&spec.Components.Callbacks, &spec.Components.PathItems, &spec.Components.Extensions,
+	} {
+		*section = sanitizeOptionalGenericObject(*section)
+	}
+	for i := range spec.Servers {
+		spec.Servers[i].Extensions = sanitizeOptionalGenericObject(spec.Servers[i].Extensions)
+	}
+	for i := range spec.Tags {
+		spec.Tags[i].Extensions = sanitizeOptionalGenericObject(spec.Tags[i].Extensions)
+	}
 	return spec
 }
 
+// sanitizeOptionalGenericObject sanitizes the object, an absent object is left nil
+func sanitizeOptionalGenericObject(in model.GenericObject) model.GenericObject {
+	if in == nil {
+		return nil
+	}
+	return sanitizeGenericObject(in)
+}
+
 func sanitizeInterfaceArray[R any](in []R) []R {
 	res := make([]R, len(in))
 	for i, v := range in {
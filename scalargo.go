@@ -1,8 +1,11 @@
 package scalargo
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"html/template"
+	"maps"
 	"strings"
 
 	"github.com/bdpiprava/scalar-go/loader"
@@ -19,18 +22,13 @@ func New(apiFilesDir string, opts ...Option) (string, error) {
 
 // NewV2 generate the HTML for the Scalar UI
 func NewV2(opts ...Option) (string, error) {
-	options := buildOptions(opts...)
-	specScript, err := options.GetSpecScript()
+	renderer, err := NewRenderer(opts...)
 	if err != nil {
 		return "", err
 	}
+	defer renderer.Close()
 
-	return renderHTML(
-		fmt.Sprintf("%v", options.Configurations[keyMetaData].(MetaData)["title"]),
-		options.OverrideCSS,
-		specScript,
-		options.CDN,
-	), nil
+	return renderer.Render(context.Background())
 }
 
 // buildOptions build Options from applying OptionFn to defaults
@@ -46,6 +44,7 @@ func buildOptions(opts ...Option) *Options {
 
 		CDN:          DefaultCDN,
 		BaseFileName: "api.yaml",
+		CacheControl: defaultCacheControl,
 	}
 
 	for _, opt := range opts {
@@ -54,74 +53,116 @@ func buildOptions(opts ...Option) *Options {
 	return options
 }
 
-// renderHTML generte html from the provided options
-func renderHTML(title, ccsOverride, specScript, cdn string) string {
-	return fmt.Sprintf(`
-    <!DOCTYPE html>
-    <html>
-      <head>
-        <title>%s</title>
-        <meta charset="utf-8" />
-        <meta name="viewport" content="width=device-width, initial-scale=1" />
-        <style>%s</style>
-      </head>
-      <body>
-        %s
-        <script src="%s"></script>
-      </body>
-    </html>
-  `, title, ccsOverride, specScript, cdn)
-}
-
-// GetSpecScript prepares and returns the spec script, prioritizing SpecURL, then SpecDirectory, then SpecBytes
+// GetSpecScript prepares and returns the spec script, prioritizing SpecURL, then SpecDirectory, then SpecBytes.
+// The spec from SpecURL is inlined when it is fetched on the server, see WithSpecFetchOpts.
 func (o *Options) GetSpecScript() (string, error) {
-	configAsBytes, err := json.Marshal(o.Configurations)
+	data := &TemplateData{SpecURL: strings.TrimSpace(o.SpecURL)}
+
+	var spec *model.Spec
+	if data.SpecURL == "" || o.fetchesSpec() {
+		var err error
+		if spec, _, err = o.loadSpec(context.Background()); err != nil {
+			return "", err
+		}
+		data.SpecURL = ""
+
+		specJSON, err := json.Marshal(spec)
+		if err != nil {
+			return "", err
+		}
+		data.Spec = template.JS(specJSON) // #nosec G203 -- json.Marshal escapes <, > and &
+	}
+
+	configJSON, err := configurationJSON(o.configurationFor(spec))
 	if err != nil {
 		return "", err
 	}
-	configJSON := strings.ReplaceAll(string(configAsBytes), `"`, `&quot;`)
-
-	if strings.TrimSpace(o.SpecURL) != "" {
-		return fmt.Sprintf(
-			`<script id="api-reference" data-url="%s" data-configuration="%s"></script>`,
-			o.SpecURL,
-			configJSON,
-		), nil
+	data.Configuration = configJSON
+
+	var script strings.Builder
+	if err := baseTemplate.ExecuteTemplate(&script, "scalar-spec", data); err != nil {
+		return "", err
 	}
+	return strings.TrimSpace(script.String()), nil
+}
 
+// loadSpec loads the spec from SpecURL when fetched on the server, SpecDirectory or SpecBytes and runs
+// the transformers on it, the files read are returned when loaded from SpecDirectory
+func (o *Options) loadSpec(ctx context.Context) (*model.Spec, loader.Files, error) {
 	var spec *model.Spec
+	var files loader.Files
+	var err error
 	switch {
+	case o.fetchesSpec():
+		spec, err = o.fetchSpec(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+	case o.SpecFS != nil:
+		spec, files, err = loader.LoadFromFSWithFiles(o.SpecFS, o.SpecDirectory, o.BaseFileName, o.LoaderOptions...)
+		if err != nil {
+			return nil, nil, err
+		}
 	case o.SpecDirectory != "":
-		spec, err = loader.LoadFromDir(o.SpecDirectory, o.BaseFileName)
+		spec, files, err = loader.LoadFromDirWithFiles(o.SpecDirectory, o.BaseFileName, o.LoaderOptions...)
 		if err != nil {
-			return "", err
+			return nil, nil, err
 		}
 	case o.SpecBytes != nil:
 		spec, err = loader.LoadFromBytes(o.SpecBytes)
 		if err != nil {
-			return "", err
+			return nil, nil, err
 		}
 	default:
-		return "", fmt.Errorf("one of SpecURL, SpecDirectory or SpecBytes must be configured")
+		return nil, nil, fmt.Errorf("one of SpecURL, SpecDirectory or SpecBytes must be configured")
+	}
+
+	if err := o.transform(ctx, spec); err != nil {
+		return nil, nil, err
+	}
+	return spec, files, nil
+}
+
+// configurationFor returns a copy of Configurations with the title taken from the spec
+// when no title is configured, the Options are left untouched
+func (o *Options) configurationFor(spec *model.Spec) map[string]any {
+	configuration := maps.Clone(o.Configurations)
+	metadata := MetaData{}
+	if md, ok := configuration[keyMetaData].(MetaData); ok {
+		metadata = maps.Clone(md)
 	}
+	configuration[keyMetaData] = metadata
 
-	if o.SpecModifier != nil {
-		spec = o.SpecModifier(spec)
+	if spec == nil {
+		return configuration
 	}
 
-	metadata := o.Configurations[keyMetaData].(MetaData)
 	if title, ok := metadata["title"]; !ok || title == defaultTitle {
 		metadata["title"] = spec.Info.Title
 	}
+	return configuration
+}
+
+// sourceConfigurations returns the Scalar sources configuration of the documents
+func sourceConfigurations(documents []*document) []sourceConfiguration {
+	sources := make([]sourceConfiguration, len(documents))
+	for i, doc := range documents {
+		sources[i] = sourceConfiguration{
+			Title:   doc.title,
+			Slug:    doc.slug,
+			Default: doc.isDefault,
+			URL:     doc.remoteURL(),
+			Content: doc.specJSON,
+		}
+	}
+	return sources
+}
 
-	content, err := json.Marshal(spec)
+// configurationJSON returns the configuration as JSON
+func configurationJSON(configuration map[string]any) (string, error) {
+	configAsBytes, err := json.Marshal(configuration)
 	if err != nil {
 		return "", err
 	}
-
-	return fmt.Sprintf(
-		`<script id="api-reference" type="application/json" data-configuration="%s">%s</script>`,
-		configJSON,
-		string(content),
-	), nil
+	return string(configAsBytes), nil
 }
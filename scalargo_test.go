@@ -2,6 +2,7 @@ package scalargo_test
 
 import (
 	"encoding/json"
+	stdhtml "html"
 	"regexp"
 	"strings"
 	"testing"
@@ -9,6 +10,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	scalargo "github.com/bdpiprava/scalar-go"
+	"github.com/bdpiprava/scalar-go/data"
+	"github.com/bdpiprava/scalar-go/loader"
 	"github.com/bdpiprava/scalar-go/model"
 )
 
@@ -62,12 +65,21 @@ func Test_NewV2(t *testing.T) {
 				require.True(t, strings.HasPrefix(got.spec, `{"openapi":"3.0.0","info":{"title":"Swagger Petstore",`))
 			},
 		},
+		{
+			name:      "should render html with inline spec when spec FS is configured",
+			inputOpts: []scalargo.Option{scalargo.WithSpecFS(data.MultipleFilesSpec, "loader-multiple-files"), scalargo.WithBaseFileName("api.yml")},
+			asserter: func(t *testing.T, got html) {
+				require.Empty(t, got.specURL)
+				require.Equal(t, "Swagger Petstore", got.title)
+				require.True(t, strings.HasPrefix(got.spec, `{"openapi":"3.0.0","info":{"title":"Swagger Petstore",`))
+			},
+		},
 		{
 			name:      "should render html with inline spec when spec bytes is configured",
 			inputOpts: []scalargo.Option{scalargo.WithSpecBytes([]byte(`{"openapi":"3.0.0","info":{"title":"Swagger Petstore"}}`))},
 			asserter: func(t *testing.T, got html) {
 				require.Empty(t, got.specURL)
-				require.True(t, strings.HasPrefix(got.spec, `{"openapi":"3.0.0","info":{"title":"Swagger Petstore","version":""},"paths":{}`))
+				require.Equal(t, `{"openapi":"3.0.0","info":{"title":"Swagger Petstore","version":""}}`, got.spec)
 			},
 		},
 		{
@@ -149,7 +161,7 @@ func parseContent(content string) html {
 		return html{}
 	}
 
-	configStr := strings.ReplaceAll(getFirstGroup(configurationMatcher, content), "&quot;", `"`)
+	configStr := stdhtml.UnescapeString(getFirstGroup(configurationMatcher, content))
 	var config map[string]any
 	if len(configStr) > 0 {
 		err := json.Unmarshal([]byte(configStr), &config)
@@ -178,3 +190,55 @@ func getFirstGroup(matcher *regexp.Regexp, content string) string {
 	}
 	return ""
 }
+
+func Test_NewV2_WithLoaderOptions(t *testing.T) {
+	content, err := scalargo.NewV2(
+		scalargo.WithSpecDir("./data/refs/api"),
+		scalargo.WithLoaderOptions(loader.WithRefMode(loader.BundleRefs)),
+	)
+	require.NoError(t, err)
+
+	spec := stdhtml.UnescapeString(content)
+	require.Contains(t, spec, `"parameters":[{"$ref":"#/components/parameters/Limit"}]`)
+	require.NotContains(t, spec, ".yaml")
+}
+
+func Test_NewV2_KeepsKeyOrder(t *testing.T) {
+	content, err := scalargo.NewV2(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+		scalargo.WithSpecModifier(func(spec *model.Spec) *model.Spec {
+			spec.Paths["/owners"] = model.GenericObject{"get": model.GenericObject{"summary": "List all owners"}}
+			return spec
+		}),
+	)
+	require.NoError(t, err)
+
+	spec := parseContent(content).spec
+	require.Contains(t, spec, `"get":{"summary":"List all pets","operationId":"listPets","tags":["pets"],"parameters":[{"name":"limit","in":"query",`)
+	require.Contains(t, spec, `"schemas":{"Pet":{"type":"object","required":["id","name"],`)
+	require.Regexp(t, `"paths":\{"/pets":.*"/pets/\{petId\}":.*"/owners":`, spec)
+}
+
+func Test_NewV2_TypedSpecModifier(t *testing.T) {
+	content, err := scalargo.NewV2(
+		scalargo.WithSpecDir("./data/loader"),
+		scalargo.WithBaseFileName("pet-store.yml"),
+		scalargo.WithSpecModifier(func(spec *model.Spec) *model.Spec {
+			pets, err := spec.PathItem("/pets")
+			if err != nil {
+				return spec
+			}
+			pets.Get.Deprecated = true
+			pets.Get.Extensions = model.GenericObject{"x-sunset": "2027-01-01"}
+			_ = spec.SetPathItem("/pets", pets)
+			return spec
+		}),
+	)
+	require.NoError(t, err)
+
+	spec := parseContent(content).spec
+	require.Contains(t, spec, `"operationId":"listPets"`)
+	require.Contains(t, spec, `"deprecated":true`)
+	require.Contains(t, spec, `"x-sunset":"2027-01-01"`)
+}